@@ -0,0 +1,267 @@
+package api
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/label"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// admin restricts a handler to requests bearing the admin token.
+func (s *service) admin(handler func(*requestContext) (int, interface{}, error)) func(*requestContext) (int, interface{}, error) {
+	return func(ctx *requestContext) (int, interface{}, error) {
+		if s.adminToken == "" {
+			return http.StatusForbidden, nil, errors.New("admin routes are disabled")
+		}
+
+		token := strings.TrimPrefix(ctx.request.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			return http.StatusUnauthorized, nil, errors.New("invalid admin token")
+		}
+
+		return handler(ctx)
+	}
+}
+
+// putLabels stores labels. As labels may stop taint, or stop stopping it, every label change has taint recomputed.
+func (s *service) putLabels(ctx *requestContext) (int, interface{}, error) {
+	var req PutLabelsRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	for _, l := range req.Labels {
+		if err := l.Normalize(); err != nil {
+			return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+		}
+	}
+
+	if err := s.store.PutLabels(req.Labels...); err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	s.scheduleRecompute()
+
+	return http.StatusOK, PutLabelsResponse{Stored: len(req.Labels)}, nil
+}
+
+// importLabels stores the labels of a CSV file, or of a JSON array, as told by the request's Content-Type. Labels
+// without a source are attributed to the source query parameter.
+func (s *service) importLabels(ctx *requestContext) (int, interface{}, error) {
+	body, err := ctx.readBody()
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	source := ctx.request.URL.Query().Get("source")
+	if source == "" {
+		source = "import"
+	}
+
+	var labels []*label.Label
+
+	mediaType, _, _ := mime.ParseMediaType(ctx.request.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		labels, err = label.ParseCSV(bytes.NewReader(body), source)
+	} else {
+		labels, err = label.ParseJSON(bytes.NewReader(body), source)
+	}
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	if err := s.store.PutLabels(labels...); err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	s.scheduleRecompute()
+
+	return http.StatusOK, PutLabelsResponse{Stored: len(labels)}, nil
+}
+
+func (s *service) deleteLabel(ctx *requestContext) (int, interface{}, error) {
+	var req DeleteLabelRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	chain, err := model.ParseChain(req.Chain)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	err = s.store.DeleteLabel(chain, req.Address, req.Source)
+	if err == leveldb.ErrNotFound {
+		return http.StatusNotFound, nil, errors.Errorf("no label of %s from %s", req.Address, req.Source)
+	}
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	s.scheduleRecompute()
+
+	return http.StatusOK, struct{}{}, nil
+}
+
+func (s *service) listLabels(ctx *requestContext) (int, interface{}, error) {
+	var req ListLabelsRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	res := ListLabelsResponse{
+		Labels: []*label.Label{},
+	}
+
+	if req.Address == "" {
+		err := s.store.ForEachLabel(model.Chain(req.Chain), func(l *label.Label) error {
+			res.Labels = append(res.Labels, l)
+			return nil
+		})
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+
+		return http.StatusOK, res, nil
+	}
+
+	chain, err := model.ParseChain(req.Chain)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	labels, err := s.store.GetLabels(chain, req.Address)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	res.Labels = append(res.Labels, labels...)
+
+	return http.StatusOK, res, nil
+}
+
+// importList replaces the labels of a source with the addresses of a new version of its sanctions list or blocklist,
+// read from the request's body. The source, format, category, chain and name query parameters are passed on as the
+// list's options; the format defaults to a plain list.
+func (s *service) importList(ctx *requestContext) (int, interface{}, error) {
+	body, err := ctx.readBody()
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	query := ctx.request.URL.Query()
+
+	source := query.Get("source")
+	if source == "" {
+		return http.StatusBadRequest, nil, errors.New("invalid request: no source given")
+	}
+
+	format := label.ListPlain
+	if f := query.Get("format"); f != "" {
+		if format, err = label.ParseListFormat(f); err != nil {
+			return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+		}
+	}
+
+	opts := label.ListOptions{
+		Source: source,
+		Name:   query.Get("name"),
+	}
+
+	if c := query.Get("category"); c != "" {
+		if opts.Category, err = label.ParseCategory(c); err != nil {
+			return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+		}
+	}
+
+	if opts.Chain, err = model.ParseChain(query.Get("chain")); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	list, err := label.ParseList(bytes.NewReader(body), format, opts)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	version, err := s.store.ImportList(source, list.Labels)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	s.scheduleRecompute()
+
+	res := ImportListResponse{
+		ListVersion: version,
+		Skipped:     list.Skipped,
+	}
+	if res.Skipped == nil {
+		res.Skipped = []string{}
+	}
+
+	return http.StatusOK, res, nil
+}
+
+func (s *service) listVersions(ctx *requestContext) (int, interface{}, error) {
+	var req ListVersionsRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	versions, err := s.store.GetListVersions(req.Source)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	res := ListVersionsResponse{
+		Versions: append([]*database.ListVersion{}, versions...),
+	}
+
+	return http.StatusOK, res, nil
+}
+
+// addressLabels returns the labels of address for display.
+func (s *service) addressLabels(chain model.Chain, address string) ([]*AddressLabel, error) {
+	labels, err := s.store.GetLabels(chain, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return displayLabels(labels), nil
+}
+
+func displayLabels(labels []*label.Label) []*AddressLabel {
+	var list []*AddressLabel
+	for _, l := range labels {
+		list = append(list, &AddressLabel{
+			Category:   string(l.Category),
+			Name:       l.Name,
+			Source:     l.Source,
+			Confidence: l.Confidence,
+		})
+	}
+
+	return list
+}
+
+// getPolicy returns the active scoring policy.
+func (s *service) getPolicy(ctx *requestContext) (int, interface{}, error) {
+	return http.StatusOK, s.policies.Policy(), nil
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// defaultActor is the actor recorded in the audit trail for admin requests that don't name one.
+const defaultActor = "admin"
+
+// putAllowlist allowlists an address. As allowlisting caps taint, every allowlist change has taint recomputed, and so
+// does the expiry of an entry.
+func (s *service) putAllowlist(ctx *requestContext) (int, interface{}, error) {
+	var req PutAllowlistRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	chain, err := model.ParseChain(req.Chain)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	if req.Actor == "" {
+		req.Actor = defaultActor
+	}
+
+	e := &database.AllowlistEntry{
+		Chain:     chain,
+		Address:   req.Address,
+		Reason:    req.Reason,
+		Actor:     req.Actor,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	if err := s.store.PutAllowlistEntry(e); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+	s.scheduleRecompute()
+	s.recomputeOnExpiry(e)
+
+	e.Address = model.DisplayAddress(e.Chain, e.Address)
+
+	return http.StatusOK, e, nil
+}
+
+func (s *service) deleteAllowlist(ctx *requestContext) (int, interface{}, error) {
+	var req DeleteAllowlistRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	chain, err := model.ParseChain(req.Chain)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	if req.Actor == "" {
+		req.Actor = defaultActor
+	}
+
+	err = s.store.DeleteAllowlistEntry(chain, req.Address, req.Actor, req.Reason)
+	if err == leveldb.ErrNotFound {
+		return http.StatusNotFound, nil, errors.Errorf("%s isn't allowlisted", req.Address)
+	}
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	s.scheduleRecompute()
+
+	return http.StatusOK, struct{}{}, nil
+}
+
+func (s *service) listAllowlist(ctx *requestContext) (int, interface{}, error) {
+	var req ListAllowlistRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	res := ListAllowlistResponse{
+		Entries: []*database.AllowlistEntry{},
+	}
+
+	now := time.Now().Unix()
+	err := s.store.ForEachAllowlistEntry(model.Chain(req.Chain), func(e *database.AllowlistEntry) error {
+		if req.Expired || !e.Expired(now) {
+			e.Address = model.DisplayAddress(e.Chain, e.Address)
+			res.Entries = append(res.Entries, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	return http.StatusOK, res, nil
+}
+
+func (s *service) auditLog(ctx *requestContext) (int, interface{}, error) {
+	var req AuditLogRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	chain := model.Chain(req.Chain)
+
+	res := AuditLogResponse{
+		Events: []*database.AuditEvent{},
+	}
+
+	err := s.store.ForEachAuditEvent(req.Since, func(e *database.AuditEvent) error {
+		if (chain != "" && e.Chain != chain) ||
+			(req.Address != "" && e.Address != model.NormalizeAddress(e.Chain, req.Address)) ||
+			(req.Action != "" && string(e.Action) != req.Action) {
+			return nil
+		}
+
+		e.Address = model.DisplayAddress(e.Chain, e.Address)
+		if e.Source != "" {
+			e.Source = model.DisplayAddress(e.Chain, e.Source)
+		}
+
+		res.Events = append(res.Events, e)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	return http.StatusOK, res, nil
+}
+
+// recomputeOnExpiry schedules a recomputation of taint for when the allowlist entry e expires, lifting its cap.
+func (s *service) recomputeOnExpiry(e *database.AllowlistEntry) {
+	if e.ExpiresAt == 0 {
+		return
+	}
+
+	time.AfterFunc(time.Until(time.Unix(e.ExpiresAt, 0)), s.scheduleRecompute)
+}
+
+// recomputeOnExpiries schedules a recomputation of taint for the expiry of every allowlist entry that didn't expire
+// before the last finished recomputation started. Entries that expired since have taint recomputed at once.
+func (s *service) recomputeOnExpiries() error {
+	state, err := s.store.GetRecomputeState()
+	if err != nil {
+		return err
+	}
+
+	var since int64
+	if state != nil && state.Finished() {
+		since = state.StartedAt
+	}
+
+	return s.store.ForEachAllowlistEntry("", func(e *database.AllowlistEntry) error {
+		if e.ExpiresAt > since {
+			s.recomputeOnExpiry(e)
+		}
+		return nil
+	})
+}
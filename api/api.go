@@ -1,11 +1,13 @@
 package api
 
 import (
+	"github.com/perlin-network/safu-go/crawler"
 	"github.com/perlin-network/safu-go/database"
-	"github.com/perlin-network/safu-go/etherscan"
 	"github.com/perlin-network/safu-go/ledger"
+	"github.com/perlin-network/safu-go/policy"
 	"gopkg.in/go-playground/validator.v9"
 	"net/http"
+	"strings"
 
 	"github.com/perlin-network/safu-go/log"
 	"github.com/rs/cors"
@@ -17,6 +19,37 @@ const (
 	RouteAllScamReports = "/all_scam_reports"
 	RouteGraph          = "/graph"
 	RouteEthGraph       = "/eth_graph"
+	RoutePath           = "/path"
+	RouteSubgraph       = "/subgraph"
+	RouteExportGraph    = "/export_graph"
+	RouteScoreHistory   = "/score_history"
+	RouteEditReport     = "/edit_report"
+	RouteRetractReport  = "/retract_report"
+	RouteReportVersions = "/report_versions"
+	RouteModerateReport = "/moderate_report"
+
+	RouteDisputeChallenge = "/dispute_challenge"
+	RouteDisputeReport    = "/dispute_report"
+
+	RoutePutLabels    = "/admin/put_labels"
+	RouteImportLabels = "/admin/import_labels"
+	RouteDeleteLabel  = "/admin/delete_label"
+	RouteListLabels   = "/admin/list_labels"
+	RouteImportList   = "/admin/import_list"
+	RouteListVersions = "/admin/list_versions"
+
+	RoutePutAllowlist    = "/admin/put_allowlist"
+	RouteDeleteAllowlist = "/admin/delete_allowlist"
+	RouteListAllowlist   = "/admin/list_allowlist"
+	RouteAuditLog        = "/admin/audit_log"
+
+	RouteDeleteReport = "/admin/delete_report"
+	RouteReviewQueue  = "/admin/review_queue"
+
+	RoutePolicy = "/admin/policy"
+
+	RouteRecompute       = "/admin/recompute"
+	RouteRecomputeStatus = "/admin/recompute_status"
 )
 
 var (
@@ -25,9 +58,21 @@ var (
 
 // service represents a service.
 type service struct {
-	esClient *etherscan.ESClient
-	store    *database.TieDotStore
-	ledger   *ledger.Ledger
+	sources    *crawler.Registry
+	store      *database.TieDotStore
+	ledger     *ledger.Ledger
+	adminToken string
+	policies   *policy.Engine
+
+	// moderators holds the hex encoded public keys of the accounts that may moderate reports.
+	moderators map[string]bool
+
+	// recomputes is signaled when a change can only be reflected in stored taint by recomputing it, such as a report
+	// being retracted or deleted, or a label stopping taint.
+	recomputes chan struct{}
+
+	// crawls holds a token for each crawl requested through the path route, bounding how many run at once.
+	crawls chan struct{}
 }
 
 // init registers routes to the HTTP serve mux.
@@ -37,20 +82,70 @@ func (s *service) init(mux *http.ServeMux) {
 	mux.HandleFunc(RouteAllScamReports, s.wrap(s.allScamReports))
 	mux.HandleFunc(RouteGraph, s.wrap(s.getGraph))
 	mux.HandleFunc(RouteEthGraph, s.wrap(s.allVertices))
+	mux.HandleFunc(RoutePath, s.wrap(s.findPaths))
+	mux.HandleFunc(RouteSubgraph, s.wrap(s.getSubgraph))
+	mux.HandleFunc(RouteExportGraph, s.wrap(s.exportGraph))
+	mux.HandleFunc(RouteScoreHistory, s.wrap(s.scoreHistory))
+	mux.HandleFunc(RouteEditReport, s.wrap(s.editReport))
+	mux.HandleFunc(RouteRetractReport, s.wrap(s.retractReport))
+	mux.HandleFunc(RouteReportVersions, s.wrap(s.reportVersions))
+	mux.HandleFunc(RouteModerateReport, s.wrap(s.moderateReport))
+	mux.HandleFunc(RouteDisputeChallenge, s.wrap(s.disputeChallenge))
+	mux.HandleFunc(RouteDisputeReport, s.wrap(s.disputeReport))
+
+	mux.HandleFunc(RoutePutLabels, s.wrap(s.admin(s.putLabels)))
+	mux.HandleFunc(RouteImportLabels, s.wrap(s.admin(s.importLabels)))
+	mux.HandleFunc(RouteDeleteLabel, s.wrap(s.admin(s.deleteLabel)))
+	mux.HandleFunc(RouteListLabels, s.wrap(s.admin(s.listLabels)))
+	mux.HandleFunc(RouteImportList, s.wrap(s.admin(s.importList)))
+	mux.HandleFunc(RouteListVersions, s.wrap(s.admin(s.listVersions)))
+
+	mux.HandleFunc(RoutePutAllowlist, s.wrap(s.admin(s.putAllowlist)))
+	mux.HandleFunc(RouteDeleteAllowlist, s.wrap(s.admin(s.deleteAllowlist)))
+	mux.HandleFunc(RouteListAllowlist, s.wrap(s.admin(s.listAllowlist)))
+	mux.HandleFunc(RouteAuditLog, s.wrap(s.admin(s.auditLog)))
+
+	mux.HandleFunc(RouteDeleteReport, s.wrap(s.admin(s.deleteReport)))
+	mux.HandleFunc(RouteReviewQueue, s.wrap(s.admin(s.reviewQueue)))
+
+	mux.HandleFunc(RoutePolicy, s.wrap(s.admin(s.getPolicy)))
+
+	mux.HandleFunc(RouteRecompute, s.wrap(s.admin(s.recompute)))
+	mux.HandleFunc(RouteRecomputeStatus, s.wrap(s.admin(s.recomputeStatus)))
 }
 
 // Run runs the API server with a specified set of options.
-func Run(serverAddr string, esClient *etherscan.ESClient, store *database.TieDotStore, ledger *ledger.Ledger) {
+// Reported addresses are crawled using the source registered for their chain.
+// Admin routes require the adminToken as a bearer token, and are disabled if adminToken is empty.
+// Addresses are scored by the active policy of policies.
+// Reports are moderated by the accounts whose hex encoded public keys are moderators.
+func Run(serverAddr string, sources *crawler.Registry, store *database.TieDotStore, ledger *ledger.Ledger, adminToken string, policies *policy.Engine, moderators []string) {
 	mux := http.NewServeMux()
 
 	service := &service{
-		esClient: esClient,
-		store:    store,
-		ledger:   ledger,
+		sources:    sources,
+		store:      store,
+		ledger:     ledger,
+		adminToken: adminToken,
+		policies:   policies,
+		moderators: make(map[string]bool),
+
+		recomputes: make(chan struct{}, 1),
+		crawls:     make(chan struct{}, MaxPathCrawls),
+	}
+
+	for _, m := range moderators {
+		service.moderators[strings.ToLower(m)] = true
 	}
 
 	service.init(mux)
 
+	go service.recomputeScheduled()
+
+	if err := service.recomputeOnExpiries(); err != nil {
+		log.Warn().Err(err).Msg("Failed to schedule the recomputation of taint for allowlist expiries.")
+	}
+
 	handler := cors.AllowAll().Handler(mux)
 
 	server := &http.Server{
@@ -36,22 +36,33 @@ type ErrorResponse struct {
 // readJSON decodes a HTTP requests JSON body into a struct.
 // Can call this once per request
 func (c *requestContext) readJSON(out interface{}) error {
+	data, err := c.readBody()
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(data, out); err != nil {
+		return errors.Wrap(err, "malformed json")
+	}
+	return nil
+}
+
+// readBody reads a HTTP request's raw body.
+// Can call this once per request
+func (c *requestContext) readBody() ([]byte, error) {
 	r := io.LimitReader(c.request.Body, MaxRequestBodySize)
 	defer c.request.Body.Close()
 
 	data, err := ioutil.ReadAll(r)
 	if err != nil {
-		return errors.Wrap(err, "bad request body")
+		return nil, errors.Wrap(err, "bad request body")
 	}
 
 	if len(data) == 0 {
-		return ErrMsgBodyNil
+		return nil, ErrMsgBodyNil
 	}
 
-	if err = json.Unmarshal(data, out); err != nil {
-		return errors.Wrap(err, "malformed json")
-	}
-	return nil
+	return data, nil
 }
 
 // WriteJSON will write a given status code & JSON to a response.
@@ -67,6 +78,20 @@ func (c *requestContext) WriteJSON(status int, data interface{}) {
 	c.response.Write(out)
 }
 
+// rawResponse is a response body written as is, instead of being encoded to JSON.
+type rawResponse struct {
+	contentType string
+	body        []byte
+}
+
+// writeRaw will write a given status code & raw body to a response.
+// Should call this once per request
+func (c *requestContext) writeRaw(status int, res *rawResponse) {
+	c.response.Header().Set("Content-Type", res.contentType)
+	c.response.WriteHeader(status)
+	c.response.Write(res.body)
+}
+
 // wrap applies middleware to a HTTP request handler.
 func (s *service) wrap(handler func(*requestContext) (int, interface{}, error)) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -113,6 +138,11 @@ func (s *service) wrap(handler func(*requestContext) (int, interface{}, error))
 				Str("path", r.URL.EscapedPath()).
 				Msg(" ")
 
+			if raw, ok := data.(*rawResponse); ok {
+				c.writeRaw(statusCode, raw)
+				return
+			}
+
 			c.WriteJSON(statusCode, data)
 		}
 	}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+)
+
+// disputeChallenge returns the message the holder of the address r targets signs to dispute the current version of
+// r with evidence. It names the version, so that a signature can't be replayed once the report changes, and the
+// keccak256 hash of the evidence, so that a signature can't be attached to other evidence.
+func disputeChallenge(r *database.Report, evidence string) string {
+	return fmt.Sprintf("I control %s and dispute SAFU report %s at version %d with evidence 0x%x.",
+		model.DisplayAddress(r.Chain, r.ScammerAddress), r.ID, r.Version, keccak256([]byte(evidence)))
+}
+
+// disputableReport returns the report id, if its target can prove control of the reported address.
+func (s *service) disputableReport(id string) (*database.Report, int, error) {
+	r, status, err := s.getReport(id)
+	if err != nil {
+		return nil, status, err
+	}
+
+	if r.Chain.UTXO() {
+		return nil, http.StatusBadRequest, errors.Errorf("invalid request: reports against %s addresses can't be disputed", r.Chain)
+	}
+
+	return r, http.StatusOK, nil
+}
+
+func (s *service) disputeChallenge(ctx *requestContext) (int, interface{}, error) {
+	var req DisputeChallengeRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	r, status, err := s.disputableReport(req.ReportID)
+	if err != nil {
+		return status, nil, err
+	}
+
+	res := DisputeChallengeResponse{
+		ReportID: r.ID,
+		Version:  r.Version,
+		Address:  model.DisplayAddress(r.Chain, r.ScammerAddress),
+		Message:  disputeChallenge(r, req.Evidence),
+	}
+
+	return http.StatusOK, res, nil
+}
+
+func (s *service) disputeReport(ctx *requestContext) (int, interface{}, error) {
+	var req DisputeReportRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	r, status, err := s.disputableReport(req.ReportID)
+	if err != nil {
+		return status, nil, err
+	}
+
+	if r.Version != req.Version {
+		return http.StatusConflict, nil, database.ErrReportChanged
+	}
+
+	signer, err := recoverPersonalSign([]byte(disputeChallenge(r, req.Evidence)), req.Signature)
+	if err != nil {
+		return http.StatusUnauthorized, nil, err
+	}
+
+	if model.NormalizeAddress(r.Chain, signer) != model.NormalizeAddress(r.Chain, r.ScammerAddress) {
+		return http.StatusForbidden, nil, errors.New("only the holder of the reported address can dispute a report")
+	}
+
+	if !r.CanDispute() {
+		return http.StatusConflict, nil, errors.Errorf("a %s report can't be disputed", r.Status)
+	}
+
+	from := r.Status
+	r.Status = database.ReportDisputed
+	r.Dispute = &database.Dispute{
+		Status:    database.DisputeOpen,
+		Evidence:  req.Evidence,
+		Signature: req.Signature,
+		OpenedAt:  time.Now().Unix(),
+	}
+
+	err = s.store.UpdateReport(r, &database.ReportVersion{
+		Action:    database.ReportActionDispute,
+		Actor:     model.DisplayAddress(r.Chain, signer),
+		Signature: req.Signature,
+	})
+	if err != nil {
+		return reportChangeStatus(err), nil, err
+	}
+
+	s.reportStatusChanged(r, from)
+
+	return http.StatusOK, ReportChangeResponse{ID: r.ID, Version: r.Version, Status: string(r.Status)}, nil
+}
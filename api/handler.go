@@ -1,13 +1,19 @@
 package api
 
 import (
+	"bytes"
 	"encoding/base64"
 	"fmt"
+	"github.com/perlin-network/safu-go/crawler"
 	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/export"
 	"github.com/perlin-network/safu-go/model"
+	"github.com/perlin-network/safu-go/taint"
 	"github.com/pkg/errors"
 	"log"
+	"math/big"
 	"net/http"
+	"time"
 )
 
 func (s *service) postScamReport(ctx *requestContext) (int, interface{}, error) {
@@ -21,14 +27,49 @@ func (s *service) postScamReport(ctx *requestContext) (int, interface{}, error)
 		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
 	}
 
+	if status, err := signedRequest(req.AccountID, &req, &req.Proof); err != nil {
+		return status, nil, err
+	}
+
+	direction, err := model.ParseDirection(req.Direction)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	chain, err := model.ParseChain(req.Chain)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	scammer, err := model.ParseAddress(chain, req.ScammerAddress)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid scammer_address")
+	}
+
+	victim, err := model.ParseAddress(chain, req.VictimAddress)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid victim_address")
+	}
+
+	// the graphs of UTXO chains aren't crawled, but ingested from blocks ahead of time
+	var source crawler.Source
+	if !chain.UTXO() {
+		source, err = s.sources.Source(chain)
+		if err != nil {
+			return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+		}
+	}
+
 	report := database.Report{
-		ScammerAddress: req.ScammerAddress,
-		VictimAddress:  req.VictimAddress,
+		Chain:          chain,
+		ScammerAddress: scammer.Key(),
+		VictimAddress:  victim.Key(),
 		Title:          req.Title,
 		Content:        req.Content,
 		Proof:          req.Proof,
 		Timestamp:      req.Timestamp,
 		AccountID:      req.AccountID,
+		Direction:      direction,
 	}
 
 	id, err := s.store.AddReport(report)
@@ -45,79 +86,502 @@ func (s *service) postScamReport(ctx *requestContext) (int, interface{}, error)
 	// TODO: add to the list of scam reports the list of accounts that reported it
 
 	go func() {
-		vertices := make(map[string]*model.Vertex)
-
-		err := s.store.BFSEthAccount(req.ScammerAddress, database.ES_API_KEY, func(tx *database.EthTransaction) {
-			fromV, ok := vertices[tx.From]
-			if !ok {
-				fromV = model.NewVertex(tx.From)
-				vertices[tx.From] = fromV
-			}
+		changes := &database.ChangeSet{Chain: chain, Sources: []string{scammer.Key()}}
 
-			toV, ok := vertices[tx.To]
-			if !ok {
-				toV = model.NewVertex(tx.To)
-				vertices[tx.To] = toV
+		if source != nil {
+			graph, err := crawler.Crawl(source, chain, scammer.Key(), direction)
+			if err != nil {
+				log.Println("crawl failed:", err)
+				return
 			}
+			changes.Edges = graph.Edges()
 
-			fromV.Children[tx.To] = struct{}{}
-			toV.Parents[tx.From] = struct{}{}
-		})
+			log.Println("finish crawling")
+		}
 
-		if err != nil {
-			log.Println("bfs failed")
+		if err := s.store.ApplyChanges(changes, s.policies.Policy()); err != nil {
+			log.Println("updating taint failed:", err)
 			return
 		}
 
-		verticesArray := make([]*model.Vertex, 0, len(vertices))
-		for _, v := range vertices {
-			verticesArray = append(verticesArray, v)
+		if err := s.rescore(chain, scammer.Key()); err != nil {
+			log.Println("recording score failed:", err)
 		}
+	}()
+
+	return http.StatusOK, res, nil
+}
+
+func (s *service) queryAddress(ctx *requestContext) (int, interface{}, error) {
+	var req QueryAddressRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	chain, err := model.ParseChain(req.Chain)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	target, err := model.ParseAddress(chain, req.TargetAddress)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid target_address")
+	}
+
+	// only live scores are recorded in the score history
+	asOf, live := req.AsOf, req.AsOf <= 0
+	if live {
+		asOf = time.Now().Unix()
+	}
+
+	p := s.policies.Policy()
+
+	labels, err := s.store.GetLabels(chain, target.Key())
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	scored, err := s.scoreAddress(chain, target.Key(), asOf, p, labels)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
 
-		if err := s.store.InsertGraph(verticesArray...); err != nil {
-			log.Println("insert error:", err)
+	if live {
+		if err := s.snapshot(chain, target.Key(), asOf, p, scored); err != nil {
+			log.Println("recording score failed:", err)
 		}
+	}
 
-		s.store.TaintBFS(req.ScammerAddress, 100)
+	if req.BandOnly {
+		return http.StatusOK, QueryBandResponse{
+			Chain:         string(chain),
+			TargetAddress: target.String(),
+			RiskBand:      scored.band,
+			Sanctioned:    len(scored.sanctions) > 0,
+			PolicyVersion: p.Version,
+		}, nil
+	}
 
-		log.Println("finish crawling")
-	}()
+	taints, err := s.store.GetTaints(chain, target.Key())
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	var res = QueryAddressResponse{
+		Chain:          string(chain),
+		TargetAddress:  target.String(),
+		TaintScore:     int32(scored.Score),
+		AssetTaints:    []*AssetTaint{},
+		RiskBand:       scored.band,
+		Categories:     scored.categories,
+		AsOf:           asOf,
+		PolicyVersion:  p.Version,
+		ScoreBreakdown: scored.Breakdown,
+		Reports:        scored.reports.Contributions,
+		Disputes:       scored.reports.disputes,
+		Labels:         displayLabels(labels),
+	}
+
+	res.SanctionsSources = scored.sanctions
+	res.Sanctioned = len(res.SanctionsSources) > 0
+
+	if scored.allowed != nil {
+		res.Allowlist = &AllowlistStatus{Reason: scored.allowed.Reason, ExpiresAt: scored.allowed.ExpiresAt}
+	}
+
+	if scored.risk != nil {
+		res.GraphRisk = scored.risk.Score
+	}
+
+	member, err := s.store.GetCluster(chain, target.Key())
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	if member != nil {
+		res.ClusterID = model.DisplayAddress(chain, member.ClusterID)
+		res.ClusterEvidence = member.Evidence
+	}
+
+	for _, t := range taints {
+		// funds that hadn't reached the address yet don't taint it
+		if t.Since > asOf {
+			continue
+		}
+
+		score := taint.Decay(t.Score, t.Since, asOf, time.Duration(p.HalfLife))
+		if scored.allowed != nil {
+			score = p.CapAllowlisted(score)
+		}
+
+		res.AssetTaints = append(res.AssetTaints, &AssetTaint{
+			Asset:  t.Asset,
+			Symbol: t.Symbol,
+			Taint:  score,
+			Since:  t.Since,
+		})
+	}
 
 	return http.StatusOK, res, nil
 }
 
-func (s *service) queryAddress(ctx *requestContext) (int, interface{}, error) {
-	var req QueryAddressRequest
+func (s *service) scoreHistory(ctx *requestContext) (int, interface{}, error) {
+	var req ScoreHistoryRequest
 
 	if err := ctx.readJSON(&req); err != nil {
 		return http.StatusBadRequest, nil, err
 	}
 
-	accountRepScores, err := s.getAccountRepScores(req.TargetAddress)
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	chain, err := model.ParseChain(req.Chain)
 	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	target, err := model.ParseAddress(chain, req.TargetAddress)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid target_address")
+	}
+
+	if req.To > 0 && req.To < req.From {
+		return http.StatusBadRequest, nil, errors.New("invalid request: to is before from")
+	}
+
+	snapshots, err := s.store.GetScoreHistory(chain, target.Key(), req.From, req.To)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	for _, snapshot := range snapshots {
+		snapshot.Address = target.String()
+	}
+
+	return http.StatusOK, ScoreHistoryResponse{
+		Chain:         string(chain),
+		TargetAddress: target.String(),
+		Snapshots:     snapshots,
+	}, nil
+}
+
+const (
+	// DefaultPathDepth is the default bound on the number of hops of the paths found between two addresses.
+	DefaultPathDepth = 6
+
+	// MaxPathCrawlDepth bounds the number of hops crawled before searching for paths, whatever their depth.
+	MaxPathCrawlDepth = 3
+
+	// MaxPathCrawls is the number of crawls requested through the path route that may run at once.
+	MaxPathCrawls = 2
+)
+
+func (s *service) findPaths(ctx *requestContext) (int, interface{}, error) {
+	var req PathRequest
+
+	if err := ctx.readJSON(&req); err != nil {
 		return http.StatusBadRequest, nil, err
 	}
-	if accountRepScores > 30 {
-		accountRepScores = 30
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	chain, err := model.ParseChain(req.Chain)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	from, err := model.ParseAddress(chain, req.From)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid from")
+	}
+
+	to, err := model.ParseAddress(chain, req.To)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid to")
+	}
+
+	k := req.K
+	if k == 0 {
+		k = 1
+	}
+
+	maxDepth := req.MaxDepth
+	if maxDepth == 0 {
+		maxDepth = DefaultPathDepth
+	}
+
+	if req.Crawl {
+		if chain.UTXO() {
+			return http.StatusBadRequest, nil, errors.Errorf("invalid request: %s addresses are ingested from blocks, not crawled", chain)
+		}
+
+		source, err := s.sources.Source(chain)
+		if err != nil {
+			return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+		}
+
+		select {
+		case s.crawls <- struct{}{}:
+			defer func() { <-s.crawls }()
+		default:
+			return http.StatusTooManyRequests, nil, errors.New("too many crawls in progress, retry later")
+		}
+
+		crawlDepth := maxDepth
+		if crawlDepth > MaxPathCrawlDepth {
+			crawlDepth = MaxPathCrawlDepth
+		}
+
+		graph, err := crawler.CrawlDepth(source, chain, from.Key(), model.DirectionForward, crawlDepth)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+
+		if err := s.store.ApplyChanges(&database.ChangeSet{Chain: chain, Edges: graph.Edges()}, s.policies.Policy()); err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
 	}
 
-	scamReportScores, err := s.getScamReportScores(req.TargetAddress)
+	graph, err := s.store.LoadGraph(chain, from.Key(), model.DirectionForward, maxDepth)
 	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	res := PathResponse{
+		Chain: string(chain),
+		From:  from.String(),
+		To:    to.String(),
+		Paths: []*Path{},
+	}
+
+	for _, addresses := range graph.KShortestPaths(from.Key(), to.Key(), k) {
+		path := &Path{Hops: []*Hop{}}
+
+		for i, address := range addresses {
+			path.Addresses = append(path.Addresses, model.DisplayAddress(chain, address))
+
+			if i == 0 {
+				continue
+			}
+
+			hop := &Hop{
+				From: model.DisplayAddress(chain, addresses[i-1]),
+				To:   model.DisplayAddress(chain, address),
+			}
+			for _, e := range graph.EdgesBetween(addresses[i-1], address) {
+				hop.Transactions = append(hop.Transactions, newTransaction(e))
+			}
+
+			path.Hops = append(path.Hops, hop)
+		}
+
+		res.Paths = append(res.Paths, path)
+	}
+
+	return http.StatusOK, res, nil
+}
+
+const (
+	// DefaultSubgraphDepth is the default number of hops of the neighborhood of an address.
+	DefaultSubgraphDepth = 2
+)
+
+func (s *service) getSubgraph(ctx *requestContext) (int, interface{}, error) {
+	var req SubgraphRequest
+
+	if err := ctx.readJSON(&req); err != nil {
 		return http.StatusBadRequest, nil, err
 	}
-	if scamReportScores > 70 {
-		accountRepScores = 70
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
 	}
-	taintScore := accountRepScores + scamReportScores
 
-	var res = QueryAddressResponse{
-		TargetAddress: req.TargetAddress,
-		TaintScore:    int32(taintScore),
+	res, status, err := s.subgraph(&req)
+	if err != nil {
+		return status, nil, err
 	}
 
 	return http.StatusOK, res, nil
 }
 
+func (s *service) exportGraph(ctx *requestContext) (int, interface{}, error) {
+	var req ExportGraphRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	name := req.Format
+	if name == "" {
+		name = ctx.request.URL.Query().Get("format")
+	}
+
+	format, err := export.ParseFormat(name)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+	if name == "" {
+		if f, ok := export.FormatFromAccept(ctx.request.Header.Get("Accept")); ok {
+			format = f
+		}
+	}
+
+	sub, status, err := s.subgraph(&req.SubgraphRequest)
+	if err != nil {
+		return status, nil, err
+	}
+
+	chain := model.Chain(sub.Chain)
+	graph := &export.Graph{Name: sub.Chain + "_" + sub.Address}
+
+	for _, n := range sub.Nodes {
+		reports, err := s.store.GetReportsByScamAddress(chain, n.ID)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+
+		node := &export.Node{ID: n.ID, Taint: n.Taint}
+		for _, r := range reports {
+			node.Reports = append(node.Reports, r.Title)
+		}
+		for _, l := range n.Labels {
+			node.Labels = append(node.Labels, l.Category+":"+l.Name)
+		}
+
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	for _, e := range sub.Edges {
+		graph.Edges = append(graph.Edges, &export.Edge{
+			ID:        e.ID,
+			Source:    e.Source,
+			Target:    e.Target,
+			TxHash:    e.TxHash,
+			Timestamp: e.Timestamp,
+			Asset:     e.Asset,
+			Symbol:    e.Symbol,
+			Amount:    e.Amount,
+			Value:     e.Value,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := export.Write(&buf, format, graph); err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	return http.StatusOK, &rawResponse{contentType: format.ContentType(), body: buf.Bytes()}, nil
+}
+
+// subgraph extracts the neighborhood of an address requested by req, returning the status code of the error if it
+// fails.
+func (s *service) subgraph(req *SubgraphRequest) (*SubgraphResponse, int, error) {
+	chain, err := model.ParseChain(req.Chain)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(err, "invalid request")
+	}
+
+	center, err := model.ParseAddress(chain, req.Address)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(err, "invalid address")
+	}
+
+	direction, err := model.ParseDirection(req.Direction)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.Wrap(err, "invalid request")
+	}
+
+	depth := req.Depth
+	if depth == 0 {
+		depth = DefaultSubgraphDepth
+	}
+
+	minValue := new(big.Float)
+	if req.MinValue != "" {
+		if _, ok := minValue.SetString(req.MinValue); !ok {
+			return nil, http.StatusBadRequest, errors.Errorf("invalid request: malformed min_value %q", req.MinValue)
+		}
+	}
+
+	graph, err := s.store.LoadGraph(chain, center.Key(), direction, depth)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	graph = graph.Filter(func(e *model.Edge) bool {
+		if e.NormalizedAmount().Cmp(minValue) < 0 {
+			return false
+		}
+		if req.Since > 0 && e.Timestamp < req.Since {
+			return false
+		}
+		if req.Until > 0 && e.Timestamp > req.Until {
+			return false
+		}
+		return true
+	})
+
+	res := &SubgraphResponse{
+		Chain:   string(chain),
+		Address: center.String(),
+		Nodes:   []*GraphNode{},
+		Edges:   []*GraphEdge{},
+	}
+
+	var addresses []string
+	var walkErr error
+
+	graph.Walk(center.Key(), direction, depth, func(address string, depth int) bool {
+		taint, err := s.maxTaint(chain, address)
+		if err != nil {
+			walkErr = err
+			return false
+		}
+
+		if depth > 0 && taint < req.MinTaint {
+			return false
+		}
+
+		labels, err := s.addressLabels(chain, address)
+		if err != nil {
+			walkErr = err
+			return false
+		}
+
+		addresses = append(addresses, address)
+		res.Nodes = append(res.Nodes, &GraphNode{
+			ID:     model.DisplayAddress(chain, address),
+			Depth:  depth,
+			Taint:  taint,
+			Labels: labels,
+		})
+
+		return true
+	})
+	if walkErr != nil {
+		return nil, http.StatusInternalServerError, walkErr
+	}
+
+	for _, e := range graph.Induced(addresses).Edges() {
+		res.Edges = append(res.Edges, &GraphEdge{
+			ID:          e.Key(),
+			Source:      model.DisplayAddress(chain, e.From),
+			Target:      model.DisplayAddress(chain, e.To),
+			Transaction: newTransaction(e),
+		})
+	}
+
+	return res, http.StatusOK, nil
+}
+
 func (s *service) getGraph(ctx *requestContext) (int, interface{}, error) {
 	graph, err := s.store.GetAllVertices()
 	if err != nil {
@@ -125,20 +589,27 @@ func (s *service) getGraph(ctx *requestContext) (int, interface{}, error) {
 	}
 
 	type vertex struct {
+		Chain   string `json:"chain"`
 		Address string `json:"address"`
 		//Parents  []string `json:"parents"`
-		Children []string `json:"children"`
+		Children []string        `json:"children"`
+		Labels   []*AddressLabel `json:"labels,omitempty"`
 	}
 
 	var list []*vertex
 
 	for _, g := range graph {
 		v := vertex{
-			Address: g.Address,
+			Chain:   string(g.Chain),
+			Address: model.DisplayAddress(g.Chain, g.Address),
 		}
 
 		for c := range g.Children {
-			v.Children = append(v.Children, c)
+			v.Children = append(v.Children, model.DisplayAddress(g.Chain, c))
+		}
+
+		if v.Labels, err = s.addressLabels(g.Chain, g.Address); err != nil {
+			return http.StatusInternalServerError, nil, err
 		}
 
 		list = append(list, &v)
@@ -149,40 +620,45 @@ func (s *service) getGraph(ctx *requestContext) (int, interface{}, error) {
 
 func (s *service) allVertices(ctx *requestContext) (int, interface{}, error) {
 	type Item struct {
+		Chain      string              `json:"chain"`
 		Address    string              `json:"address"`
 		Children   map[string]struct{} `json:"children"`
 		Parents    map[string]struct{} `json:"parents"`
+		Labels     []*AddressLabel     `json:"labels,omitempty"`
 		TaintScore int                 `json:"taint_score"`
+
+		PolicyVersion string `json:"policy_version"`
 	}
 	pret, err := s.store.GetAllVertices()
 	if err != nil {
 		return http.StatusInternalServerError, nil, err
 	}
 
+	now := time.Now().Unix()
+	p := s.policies.Policy()
+
 	ret := make([]*Item, len(pret))
 	for i, v := range pret {
 		ret[i] = &Item{
-			Address:  v.Address,
-			Children: v.Children,
-			Parents:  v.Parents,
+			Chain:         string(v.Chain),
+			Address:       model.DisplayAddress(v.Chain, v.Address),
+			Children:      displayAddresses(v.Chain, v.Children),
+			Parents:       displayAddresses(v.Chain, v.Parents),
+			PolicyVersion: p.Version,
 		}
 
-		accountRepScores, err := s.getAccountRepScores(v.Address)
+		labels, err := s.store.GetLabels(v.Chain, v.Address)
 		if err != nil {
-			continue
-		}
-		if accountRepScores > 30 {
-			accountRepScores = 30
+			return http.StatusInternalServerError, nil, err
 		}
+		ret[i].Labels = displayLabels(labels)
 
-		scamReportScores, err := s.getScamReportScores(v.Address)
+		scored, err := s.scoreAddress(v.Chain, v.Address, now, p, labels)
 		if err != nil {
 			continue
 		}
-		if scamReportScores > 70 {
-			accountRepScores = 70
-		}
-		ret[i].TaintScore = accountRepScores + scamReportScores
+
+		ret[i].TaintScore = scored.Score
 	}
 
 	return http.StatusOK, ret, nil
@@ -193,49 +669,70 @@ func (s *service) allScamReports(ctx *requestContext) (int, interface{}, error)
 		Reports: []*ScamReport{},
 	}
 	s.store.ForEachReport(func(report *database.Report) error {
-		sr := &ScamReport{
-			ID:             report.ID,
-			Timestamp:      report.Timestamp,
-			AccountID:      report.AccountID,
-			ScammerAddress: report.ScammerAddress,
-			VictimAddress:  report.VictimAddress,
-			Title:          report.Title,
-			Content:        report.Content,
-			Proof:          report.Proof,
-			Taint:          report.Taint,
-		}
-		resp.Reports = append(resp.Reports, sr)
+		resp.Reports = append(resp.Reports, newScamReport(report))
 		return nil
 	})
 	return http.StatusOK, resp, nil
 }
 
+// newScamReport describes a stored report.
+func newScamReport(report *database.Report) *ScamReport {
+	return &ScamReport{
+		ID:             report.ID,
+		Chain:          string(report.Chain),
+		Timestamp:      report.Timestamp,
+		AccountID:      report.AccountID,
+		ScammerAddress: model.DisplayAddress(report.Chain, report.ScammerAddress),
+		VictimAddress:  model.DisplayAddress(report.Chain, report.VictimAddress),
+		Title:          report.Title,
+		Content:        report.Content,
+		Proof:          report.Proof,
+		Taint:          report.Taint,
+		Direction:      string(report.Direction),
+		Status:         string(report.Status),
+		Version:        report.Version,
+		Dispute:        report.Dispute,
+	}
+}
+
 //////////////////////////////////////////////
 
-func (s *service) getAccountRepScores(targetAddress string) (int, error) {
-	reports, err := s.store.GetReportsByScamAddress(targetAddress)
+// maxTaint returns the highest taint address holds in any asset.
+func (s *service) maxTaint(chain model.Chain, address string) (int, error) {
+	taints, err := s.store.GetTaints(chain, address)
 	if err != nil {
 		return 0, err
 	}
-	var accountIDs []string
-	for _, report := range reports {
-		accountIDs = append(accountIDs, report.AccountID)
-	}
-	rep, err := s.ledger.GetReps(accountIDs)
-	if err != nil {
-		return 0, err
+
+	max := 0
+	for _, t := range taints {
+		if t.Score > max {
+			max = t.Score
+		}
 	}
-	return rep * 10, nil
+
+	return max, nil
 }
 
-func (s *service) getScamReportScores(targetAddress string) (int, error) {
-	report, err := s.store.GetReportByScamAddress(targetAddress)
-	if err != nil {
-		return 0, err
+// newTransaction describes the transfer along an edge.
+func newTransaction(e *model.Edge) *Transaction {
+	return &Transaction{
+		TxHash:      e.TxHash,
+		BlockNumber: e.BlockNumber,
+		Timestamp:   e.Timestamp,
+		Asset:       e.Asset(),
+		Symbol:      e.Symbol(),
+		Amount:      e.Value().String(),
+		Value:       e.NormalizedAmount().Text('f', -1),
+		Internal:    e.Internal,
 	}
-	taint := int(report.Taint * 7 / 10)
-	if taint > 70 {
-		taint = 70
+}
+
+// displayAddresses formats a set of stored addresses for display.
+func displayAddresses(chain model.Chain, addresses map[string]struct{}) map[string]struct{} {
+	ret := make(map[string]struct{}, len(addresses))
+	for address := range addresses {
+		ret[model.DisplayAddress(chain, address)] = struct{}{}
 	}
-	return taint, nil
+	return ret
 }
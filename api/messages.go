@@ -1,5 +1,13 @@
 package api
 
+import (
+	"github.com/perlin-network/safu-go/cluster"
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/label"
+	"github.com/perlin-network/safu-go/policy"
+	"github.com/perlin-network/safu-go/score"
+)
+
 type SubmitReportRequest struct {
 	Timestamp      int64  `json:"timestamp" validate:"required"`
 	AccountID      string `json:"account_id" validate:"required"`
@@ -7,7 +15,15 @@ type SubmitReportRequest struct {
 	VictimAddress  string `json:"victim_address" validate:"required"`
 	Title          string `json:"title" validate:"required"`
 	Content        string `json:"content" validate:"required"`
-	Proof          string `json:"proof"`
+
+	// Proof is the signature of the request, with an empty Proof, by the account AccountID.
+	Proof string `json:"proof" validate:"required"`
+
+	// Chain is the chain the addresses are on, Ethereum by default.
+	Chain string `json:"chain"`
+
+	// Direction is the crawl direction used to trace the scammer's funds: forward (default), backward or both.
+	Direction string `json:"direction" validate:"omitempty,oneof=forward backward both"`
 }
 
 type SubmitReportResponse struct {
@@ -18,15 +34,100 @@ type QueryAddressRequest struct {
 	Timestamp     int64  `json:"timestamp" validate:"required"`
 	AccountID     string `json:"account_id" validate:"required"`
 	TargetAddress string `json:"target_address" validate:"required"`
+	Chain         string `json:"chain"`
+
+	// AsOf is the unix timestamp the decayed taint score is computed as of, now by default. Reports and transfers
+	// later than AsOf are ignored.
+	AsOf int64 `json:"as_of"`
+
+	// BandOnly asks for a QueryBandResponse: the address's risk band alone, for cheap checks.
+	BandOnly bool `json:"band_only"`
 }
 
 type QueryAddressResponse struct {
+	Chain         string        `json:"chain"`
+	TargetAddress string        `json:"target_address" validate:"required"`
+	TaintScore    int32         `json:"taint_score" validate:"required"`
+	AssetTaints   []*AssetTaint `json:"asset_taints"`
+
+	// RiskBand is the band of the scoring policy TaintScore falls into, such as low or severe, and Categories tag
+	// the address with the kinds of scams the reports against it and its labels point to, such as phishing.
+	RiskBand   string   `json:"risk_band"`
+	Categories []string `json:"categories"`
+
+	// AsOf is the unix timestamp the taint scores are decayed as of.
+	AsOf int64 `json:"as_of"`
+
+	// PolicyVersion is the version of the scoring policy TaintScore was computed with, and ScoreBreakdown the part
+	// each of the policy's components takes in it.
+	PolicyVersion  string            `json:"policy_version"`
+	ScoreBreakdown *policy.Breakdown `json:"score_breakdown"`
+
+	// Reports lists how each report against the address's entity contributes to its taint score, and Disputes the
+	// disputes of those reports.
+	Reports  []*score.Contribution `json:"reports"`
+	Disputes []*ReportDispute      `json:"disputes"`
+
+	// Sanctioned is a hard flag raised when the address is on a sanctions list or blocklist, whatever its taint
+	// score. SanctionsSources names the lists.
+	Sanctioned       bool     `json:"sanctioned"`
+	SanctionsSources []string `json:"sanctions_sources,omitempty"`
+
+	// Allowlist is set when the address is allowlisted: its taint is capped, and it doesn't pass taint on.
+	Allowlist *AllowlistStatus `json:"allowlist,omitempty"`
+
+	// Labels tag the address with the entities it is known to belong to.
+	Labels []*AddressLabel `json:"labels"`
+
+	// GraphRisk is the address's personalized PageRank seeded from reported scammers, scaled from 0 to 100. It is
+	// computed by a batch job, apart from TaintScore, and is 0 for addresses the job didn't reach.
+	GraphRisk float64 `json:"graph_risk"`
+
+	// ClusterID identifies the entity the address was clustered into, if any, and ClusterEvidence lists why.
+	ClusterID       string              `json:"cluster_id,omitempty"`
+	ClusterEvidence []*cluster.Evidence `json:"cluster_evidence,omitempty"`
+}
+
+// QueryBandResponse is the risk band of an address, for wallets that only need a cheap check.
+type QueryBandResponse struct {
+	Chain         string `json:"chain"`
+	TargetAddress string `json:"target_address"`
+	RiskBand      string `json:"risk_band"`
+	Sanctioned    bool   `json:"sanctioned"`
+	PolicyVersion string `json:"policy_version"`
+}
+
+// AssetTaint is the taint an address holds in a single asset, either ETH or a token contract.
+type AssetTaint struct {
+	Asset  string `json:"asset"`
+	Symbol string `json:"symbol"`
+	Taint  int    `json:"taint"`
+
+	// Since is the unix timestamp the tainted funds reached the address at, or 0 if it isn't known.
+	Since int64 `json:"since"`
+}
+
+// ScoreHistoryRequest asks for the scores an address was computed to hold from the unix timestamp From to the unix
+// timestamp To, or up to now if To is 0.
+type ScoreHistoryRequest struct {
+	Chain         string `json:"chain"`
 	TargetAddress string `json:"target_address" validate:"required"`
-	TaintScore    int32  `json:"taint_score" validate:"required"`
+	From          int64  `json:"from"`
+	To            int64  `json:"to"`
+}
+
+// ScoreHistoryResponse is the score history of an address, oldest first. A snapshot is only taken when the score, its
+// risk band or how it is computed changes, so each snapshot holds until the next one. Until its first snapshot, an
+// address held a score of 0.
+type ScoreHistoryResponse struct {
+	Chain         string                    `json:"chain"`
+	TargetAddress string                    `json:"target_address"`
+	Snapshots     []*database.ScoreSnapshot `json:"snapshots"`
 }
 
 type ScamReport struct {
 	ID             string `json:"id"`
+	Chain          string `json:"chain"`
 	Timestamp      int64  `json:"timestamp" validate:"required"`
 	AccountID      string `json:"account_id" validate:"required"`
 	ScammerAddress string `json:"scammer_address" validate:"required"`
@@ -35,8 +136,352 @@ type ScamReport struct {
 	Content        string `json:"content" validate:"required"`
 	Proof          string `json:"proof" validate:"required"`
 	Taint          int    `json:"taint"`
+	Direction      string `json:"direction"`
+	Status         string `json:"status"`
+	Version        int    `json:"version"`
+
+	Dispute *database.Dispute `json:"dispute,omitempty"`
+}
+
+// EditReportRequest replaces the title and content of the version Version of a report, and is signed by its
+// reporter: Signature is the base64 encoded ed25519 signature, by the key of AccountID, of the blake2b hash of the
+// request's JSON with an empty signature. Changes are made to the latest version of a report only, so that they
+// can't be replayed.
+type EditReportRequest struct {
+	ReportID  string `json:"report_id" validate:"required"`
+	AccountID string `json:"account_id" validate:"required"`
+	Version   int    `json:"version" validate:"required"`
+	Title     string `json:"title" validate:"required"`
+	Content   string `json:"content" validate:"required"`
+	Signature string `json:"signature"`
+}
+
+// RetractReportRequest takes back the version Version of a report, and is signed by its reporter as an
+// EditReportRequest is. Retracted reports don't count towards scores or taint.
+type RetractReportRequest struct {
+	ReportID  string `json:"report_id" validate:"required"`
+	AccountID string `json:"account_id" validate:"required"`
+	Version   int    `json:"version" validate:"required"`
+	Reason    string `json:"reason"`
+	Signature string `json:"signature"`
+}
+
+// DeleteReportRequest deletes a report, e.g. a malicious one. Its versions are kept.
+type DeleteReportRequest struct {
+	ReportID string `json:"report_id" validate:"required"`
+	Actor    string `json:"actor"`
+	Reason   string `json:"reason" validate:"required"`
+}
+
+// ReportChangeResponse is the version of a report a change made.
+type ReportChangeResponse struct {
+	ID      string `json:"id"`
+	Version int    `json:"version"`
+	Status  string `json:"status,omitempty"`
+}
+
+// ModerateReportRequest moves the version Version of a report to the status Status, one of under_review, verified
+// or rejected, and is signed by a moderator as an EditReportRequest is by a reporter: ModeratorID is the hex encoded
+// public key of the moderator's Wavelet account. Verifying a disputed report dismisses its dispute, and rejecting it
+// upholds the dispute.
+type ModerateReportRequest struct {
+	ReportID    string `json:"report_id" validate:"required"`
+	ModeratorID string `json:"moderator_id" validate:"required"`
+	Version     int    `json:"version" validate:"required"`
+	Status      string `json:"status" validate:"required"`
+	Note        string `json:"note"`
+	Signature   string `json:"signature"`
+}
+
+// DisputeChallengeRequest asks for the message the holder of the address a report targets signs to dispute it with
+// the counter-evidence Evidence.
+type DisputeChallengeRequest struct {
+	ReportID string `json:"report_id" validate:"required"`
+	Evidence string `json:"evidence" validate:"required"`
+}
+
+// DisputeChallengeResponse is the challenge Message to sign to dispute the version Version of a report against
+// Address.
+type DisputeChallengeResponse struct {
+	ReportID string `json:"report_id"`
+	Version  int    `json:"version"`
+	Address  string `json:"address"`
+	Message  string `json:"message"`
+}
+
+// DisputeReportRequest disputes the version Version of a report, attaching the counter-evidence Evidence. The
+// holder of the reported address proves control of it by signing the report's dispute challenge for Evidence with
+// personal_sign: Signature is the hex encoded signature by the address's key. Disputed reports weigh less until a
+// moderator verifies or rejects them.
+type DisputeReportRequest struct {
+	ReportID  string `json:"report_id" validate:"required"`
+	Version   int    `json:"version" validate:"required"`
+	Evidence  string `json:"evidence" validate:"required"`
+	Signature string `json:"signature" validate:"required"`
+}
+
+// ReportDispute is the dispute of a report against an address.
+type ReportDispute struct {
+	ReportID   string `json:"report_id"`
+	Status     string `json:"status"`
+	OpenedAt   int64  `json:"opened_at"`
+	ResolvedAt int64  `json:"resolved_at,omitempty"`
+}
+
+// ReviewQueueRequest lists the reports awaiting moderation, oldest first. Statuses are submitted, under_review and
+// disputed by default. Chain, Address, Reporter and Since, a unix timestamp, further filter the reports.
+type ReviewQueueRequest struct {
+	Statuses []string `json:"statuses"`
+	Chain    string   `json:"chain"`
+	Address  string   `json:"address"`
+	Reporter string   `json:"reporter"`
+	Since    int64    `json:"since" validate:"gte=0"`
+
+	// Limit bounds the number of reports returned, 100 by default, and Offset skips reports for paging.
+	Limit  int `json:"limit" validate:"gte=0,lte=1000"`
+	Offset int `json:"offset" validate:"gte=0"`
+}
+
+// ReviewQueueResponse is a page of the review queue, and the number of reports it holds in Total.
+type ReviewQueueResponse struct {
+	Reports []*ScamReport `json:"reports"`
+	Total   int           `json:"total"`
+}
+
+type ReportVersionsRequest struct {
+	ReportID string `json:"report_id" validate:"required"`
+}
+
+type ReportVersionsResponse struct {
+	Versions []*database.ReportVersion `json:"versions"`
 }
 
 type AllScamReportResponse struct {
 	Reports []*ScamReport `json:"reports"`
 }
+
+// PathRequest asks for the paths funds may have taken from one address to another over the stored graph.
+type PathRequest struct {
+	Chain string `json:"chain"`
+	From  string `json:"from" validate:"required"`
+	To    string `json:"to" validate:"required"`
+
+	// K is the number of shortest paths to find, 1 by default.
+	K int `json:"k" validate:"omitempty,min=1,max=10"`
+
+	// Crawl makes the server crawl forward from From before searching, to find paths that weren't stored yet. The
+	// crawl goes at most 3 hops deep, and is refused with 429 Too Many Requests while other crawls are in progress.
+	Crawl bool `json:"crawl"`
+
+	// MaxDepth bounds the number of hops of the paths, and of the crawl. It is 6 by default.
+	MaxDepth int `json:"max_depth" validate:"omitempty,min=1,max=10"`
+}
+
+type PathResponse struct {
+	Chain string  `json:"chain"`
+	From  string  `json:"from"`
+	To    string  `json:"to"`
+	Paths []*Path `json:"paths"`
+}
+
+// Path is a sequence of hops funds may have taken, from sender to recipient.
+type Path struct {
+	Addresses []string `json:"addresses"`
+	Hops      []*Hop   `json:"hops"`
+}
+
+// Hop lists the transactions that moved funds from one address of a path to the next.
+type Hop struct {
+	From         string         `json:"from"`
+	To           string         `json:"to"`
+	Transactions []*Transaction `json:"transactions"`
+}
+
+// Transaction is a transfer of an asset along an edge of the graph.
+type Transaction struct {
+	TxHash      string `json:"tx_hash"`
+	BlockNumber uint64 `json:"block_number"`
+	Timestamp   int64  `json:"timestamp"`
+	Asset       string `json:"asset"`
+	Symbol      string `json:"symbol"`
+
+	// Amount is the amount transferred in the asset's base unit, and Value the same amount in whole units.
+	Amount string `json:"amount"`
+	Value  string `json:"value"`
+
+	Internal bool `json:"internal"`
+}
+
+// SubgraphRequest asks for the neighborhood of an address in the stored graph.
+type SubgraphRequest struct {
+	Chain   string `json:"chain"`
+	Address string `json:"address" validate:"required"`
+
+	// Direction is the direction edges are followed in from Address: forward (default), backward or both.
+	Direction string `json:"direction" validate:"omitempty,oneof=forward backward both"`
+
+	// Depth is the number of hops from Address, 2 by default.
+	Depth int `json:"depth" validate:"omitempty,min=1,max=6"`
+
+	// MinValue drops the transfers of less than a value, in whole units of the asset transferred.
+	MinValue string `json:"min_value" validate:"omitempty,numeric"`
+
+	// Since and Until drop the transfers made before or after a unix timestamp.
+	Since int64 `json:"since" validate:"omitempty,min=0"`
+	Until int64 `json:"until" validate:"omitempty,min=0"`
+
+	// MinTaint drops the addresses tainted less than a score, and the addresses only reached through them.
+	MinTaint int `json:"min_taint" validate:"omitempty,min=0,max=100"`
+}
+
+type SubgraphResponse struct {
+	Chain   string       `json:"chain"`
+	Address string       `json:"address"`
+	Nodes   []*GraphNode `json:"nodes"`
+	Edges   []*GraphEdge `json:"edges"`
+}
+
+// GraphNode is an address of a subgraph, identified by its address.
+type GraphNode struct {
+	ID string `json:"id"`
+
+	// Depth is the number of hops between the address and the subgraph's center.
+	Depth int `json:"depth"`
+
+	// Taint is the highest taint the address holds in any asset.
+	Taint int `json:"taint"`
+
+	Labels []*AddressLabel `json:"labels,omitempty"`
+}
+
+// GraphEdge is a transfer of a subgraph, between the nodes identified by Source and Target.
+type GraphEdge struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+
+	*Transaction
+}
+
+// ExportGraphRequest asks for the neighborhood of an address in a graph file format.
+type ExportGraphRequest struct {
+	SubgraphRequest
+
+	// Format is the file format: dot, graphml, gexf or cytoscape. When empty, the format is picked from the
+	// request's format query parameter, then from its Accept header, and defaults to Cytoscape.js JSON.
+	Format string `json:"format" validate:"omitempty,oneof=dot graphml gexf cytoscape"`
+}
+
+// AddressLabel tags an address with the entity it is known to belong to.
+type AddressLabel struct {
+	Category   string  `json:"category"`
+	Name       string  `json:"name"`
+	Source     string  `json:"source"`
+	Confidence float64 `json:"confidence"`
+}
+
+type PutLabelsRequest struct {
+	Labels []*label.Label `json:"labels" validate:"required,min=1"`
+}
+
+type PutLabelsResponse struct {
+	Stored int `json:"stored"`
+}
+
+type DeleteLabelRequest struct {
+	Chain   string `json:"chain"`
+	Address string `json:"address" validate:"required"`
+	Source  string `json:"source" validate:"required"`
+}
+
+// ListLabelsRequest lists the labels of an address, or every label of a chain if Address is empty, or every label
+// if Chain is empty too.
+type ListLabelsRequest struct {
+	Chain   string `json:"chain"`
+	Address string `json:"address"`
+}
+
+type ListLabelsResponse struct {
+	Labels []*label.Label `json:"labels"`
+}
+
+// ImportListResponse describes the version of an address list an import stored.
+type ImportListResponse struct {
+	*database.ListVersion
+
+	// Skipped holds the addresses of the list that weren't imported: malformed addresses, and addresses on chains
+	// that aren't supported.
+	Skipped []string `json:"skipped"`
+}
+
+type ListVersionsRequest struct {
+	Source string `json:"source" validate:"required"`
+}
+
+type ListVersionsResponse struct {
+	Versions []*database.ListVersion `json:"versions"`
+}
+
+// AllowlistStatus tells why an address is allowlisted, and until when.
+type AllowlistStatus struct {
+	Reason    string `json:"reason"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// PutAllowlistRequest allowlists an address. ExpiresAt is the unix timestamp the entry expires at, or 0 if it doesn't
+// expire; Actor is who allowlists it, for the audit trail.
+type PutAllowlistRequest struct {
+	Chain     string `json:"chain"`
+	Address   string `json:"address" validate:"required"`
+	Reason    string `json:"reason" validate:"required"`
+	ExpiresAt int64  `json:"expires_at" validate:"gte=0"`
+	Actor     string `json:"actor"`
+}
+
+type DeleteAllowlistRequest struct {
+	Chain   string `json:"chain"`
+	Address string `json:"address" validate:"required"`
+	Reason  string `json:"reason"`
+	Actor   string `json:"actor"`
+}
+
+// ListAllowlistRequest lists the allowlist entries of a chain, or of every chain if Chain is empty.
+type ListAllowlistRequest struct {
+	Chain string `json:"chain"`
+
+	// Expired includes expired entries.
+	Expired bool `json:"expired"`
+}
+
+type ListAllowlistResponse struct {
+	Entries []*database.AllowlistEntry `json:"entries"`
+}
+
+// AuditLogRequest lists the audit trail since a unix timestamp, optionally filtered by chain, address and action.
+type AuditLogRequest struct {
+	Since   int64  `json:"since" validate:"gte=0"`
+	Chain   string `json:"chain"`
+	Address string `json:"address"`
+	Action  string `json:"action"`
+}
+
+type AuditLogResponse struct {
+	Events []*database.AuditEvent `json:"events"`
+}
+
+// RecomputeRequest asks to recompute all taint. An interrupted recomputation is resumed unless Restart is set.
+type RecomputeRequest struct {
+	Restart bool `json:"restart"`
+}
+
+// RecomputeResponse acknowledges a recomputation started under the policy of version PolicyVersion.
+type RecomputeResponse struct {
+	PolicyVersion string `json:"policy_version"`
+}
+
+// RecomputeStatusResponse is the progress of the latest recomputation of taint, or a nil State if taint was never
+// recomputed.
+type RecomputeStatusResponse struct {
+	Running bool                     `json:"running"`
+	State   *database.RecomputeState `json:"state"`
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+)
+
+const defaultReviewQueueLimit = 100
+
+// reviewStatuses are the statuses of the reports the review queue lists by default.
+var reviewStatuses = []string{
+	string(database.ReportSubmitted),
+	string(database.ReportUnderReview),
+	string(database.ReportDisputed),
+}
+
+// isModerator returns whether the account accountID, a hex encoded public key, may moderate reports.
+func (s *service) isModerator(accountID string) bool {
+	return s.moderators[strings.ToLower(accountID)]
+}
+
+func (s *service) moderateReport(ctx *requestContext) (int, interface{}, error) {
+	var req ModerateReportRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	to, err := database.ParseReportStatus(req.Status)
+	if err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	if status, err := signedRequest(req.ModeratorID, &req, &req.Signature); err != nil {
+		return status, nil, err
+	}
+
+	if !s.isModerator(req.ModeratorID) {
+		return http.StatusForbidden, nil, errors.New("only moderators can moderate reports")
+	}
+
+	r, status, err := s.getReport(req.ReportID)
+	if err != nil {
+		return status, nil, err
+	}
+
+	if r.Version != req.Version {
+		return http.StatusConflict, nil, database.ErrReportChanged
+	}
+
+	if !r.Status.CanModerate(to) {
+		return http.StatusConflict, nil, errors.Errorf("a %s report can't be moved to %s", r.Status, to)
+	}
+
+	from := r.Status
+	r.Moderate(to, time.Now().Unix())
+
+	err = s.store.UpdateReport(r, &database.ReportVersion{
+		Action:    database.ReportActionModerate,
+		Actor:     req.ModeratorID,
+		Signature: req.Signature,
+		Reason:    req.Note,
+	})
+	if err != nil {
+		return reportChangeStatus(err), nil, err
+	}
+
+	s.reportStatusChanged(r, from)
+
+	return http.StatusOK, ReportChangeResponse{ID: r.ID, Version: r.Version, Status: string(r.Status)}, nil
+}
+
+func (s *service) reviewQueue(ctx *requestContext) (int, interface{}, error) {
+	var req ReviewQueueRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	if len(req.Statuses) == 0 {
+		req.Statuses = reviewStatuses
+	}
+
+	statuses := make(map[database.ReportStatus]bool)
+	for _, status := range req.Statuses {
+		status, err := database.ParseReportStatus(status)
+		if err != nil {
+			return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+		}
+		statuses[status] = true
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultReviewQueueLimit
+	}
+
+	chain := model.Chain(req.Chain)
+
+	var reports []*database.Report
+
+	err := s.store.ForEachReport(func(r *database.Report) error {
+		if !statuses[r.Status] ||
+			(chain != "" && r.Chain != chain) ||
+			(req.Address != "" && r.ScammerAddress != model.NormalizeAddress(r.Chain, req.Address)) ||
+			(req.Reporter != "" && r.AccountID != req.Reporter) ||
+			r.ReportedAt() < req.Since {
+			return nil
+		}
+
+		reports = append(reports, r)
+		return nil
+	})
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].ReportedAt() < reports[j].ReportedAt()
+	})
+
+	res := ReviewQueueResponse{
+		Reports: []*ScamReport{},
+		Total:   len(reports),
+	}
+
+	for i := req.Offset; i < len(reports) && i < req.Offset+req.Limit; i++ {
+		res.Reports = append(res.Reports, newScamReport(reports[i]))
+	}
+
+	return http.StatusOK, res, nil
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/model"
+)
+
+// newTestService returns a service backed by a store in a scratch directory, removed when t ends.
+func newTestService(t *testing.T) *service {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "safu_api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := database.NewTieDotStore(dir)
+	t.Cleanup(func() {
+		store.Close()
+		os.RemoveAll(dir)
+	})
+
+	return &service{store: store}
+}
+
+// call calls handler with body as the JSON body of its request.
+func call(t *testing.T, s *service, handler func(*requestContext) (int, interface{}, error), body interface{}) (int, interface{}, error) {
+	t.Helper()
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return handler(&requestContext{
+		service:  s,
+		response: httptest.NewRecorder(),
+		request:  httptest.NewRequest(http.MethodPost, "/", strings.NewReader(string(b))),
+	})
+}
+
+func TestReviewQueueTimestamps(t *testing.T) {
+	s := newTestService(t)
+	now := time.Now()
+
+	// safu_cli timestamps reports in nanoseconds, other clients in seconds or milliseconds
+	timestamps := map[string]int64{
+		"nanoseconds, 2 hours ago":  now.Add(-2 * time.Hour).UnixNano(),
+		"seconds, 1 hour ago":       now.Add(-time.Hour).Unix(),
+		"milliseconds, 3 hours ago": now.Add(-3*time.Hour).UnixNano() / 1e6,
+		"nanoseconds, 1 minute ago": now.Add(-time.Minute).UnixNano(),
+	}
+	for title, timestamp := range timestamps {
+		_, err := s.store.AddReport(database.Report{
+			Chain:          model.ChainEthereum,
+			ScammerAddress: "0x2c7536e3605d9c16a7a3d7b1898e529396a65c23",
+			Title:          title,
+			Timestamp:      timestamp,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		since int64
+		want  []string
+	}{
+		{"oldest first", 0, []string{"milliseconds, 3 hours ago", "nanoseconds, 2 hours ago", "seconds, 1 hour ago", "nanoseconds, 1 minute ago"}},
+		{"since", now.Add(-90 * time.Minute).Unix(), []string{"seconds, 1 hour ago", "nanoseconds, 1 minute ago"}},
+		{"since now", now.Unix() + 1, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, res, err := call(t, s, s.reviewQueue, ReviewQueueRequest{Since: tt.since})
+			if err != nil {
+				t.Fatalf("reviewQueue() = %d, %v", status, err)
+			}
+
+			var titles []string
+			for _, r := range res.(ReviewQueueResponse).Reports {
+				titles = append(titles, r.Title)
+			}
+
+			if !reflect.DeepEqual(titles, tt.want) {
+				t.Errorf("review queue = %v, want %v", titles, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/log"
+	"github.com/perlin-network/safu-go/model"
+)
+
+// recompute starts recomputing all taint in the background under the active policy, resuming an interrupted
+// recomputation unless asked to restart. Its progress is served by recomputeStatus.
+func (s *service) recompute(ctx *requestContext) (int, interface{}, error) {
+	var req RecomputeRequest
+
+	// the request is optional
+	if err := ctx.readJSON(&req); err != nil && err != ErrMsgBodyNil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if s.store.Recomputing() {
+		return http.StatusConflict, nil, database.ErrRecomputing
+	}
+
+	p := s.policies.Policy()
+
+	go func() {
+		state, err := s.store.RecomputeTaint(p, req.Restart, func(state *database.RecomputeState) {
+			log.Debug().Int("done", state.Done).Int("total", state.Total).Msg("Recomputing taint.")
+		})
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to recompute taint.")
+			return
+		}
+
+		log.Info().Str("id", state.ID).Int("sources", state.Total).Msg("Recomputed taint.")
+
+		if err := s.rescoreReported(); err != nil {
+			log.Warn().Err(err).Msg("Failed to record the scores of the reported addresses.")
+		}
+	}()
+
+	return http.StatusAccepted, RecomputeResponse{PolicyVersion: p.Version}, nil
+}
+
+// recomputeRetryInterval is how long a scheduled recomputation waits for a running one to finish.
+const recomputeRetryInterval = 10 * time.Second
+
+// scheduleRecompute has taint recomputed in the background. Calls made while taint is being recomputed are followed
+// by a single recomputation.
+func (s *service) scheduleRecompute() {
+	select {
+	case s.recomputes <- struct{}{}:
+	default:
+	}
+}
+
+// recomputeScheduled recomputes taint whenever it is scheduled: taint only ever grows when changes are applied
+// incrementally, so the taint of removed reports, or of addresses that labels turned into barriers, can only be removed
+// by a full recomputation.
+func (s *service) recomputeScheduled() {
+	for range s.recomputes {
+		for {
+			// the staged taint of an interrupted recomputation may predate the scheduling changes
+			state, err := s.store.RecomputeTaint(s.policies.Policy(), true, nil)
+			if err == database.ErrRecomputing {
+				time.Sleep(recomputeRetryInterval)
+				continue
+			}
+			if err != nil {
+				log.Warn().Err(err).Msg("Failed to recompute scheduled taint.")
+				break
+			}
+
+			log.Info().Str("id", state.ID).Int("sources", state.Total).Msg("Recomputed scheduled taint.")
+
+			if err := s.rescoreReported(); err != nil {
+				log.Warn().Err(err).Msg("Failed to record the scores of the reported addresses.")
+			}
+			break
+		}
+	}
+}
+
+// rescoreReported records the score of every reported address in its score history.
+func (s *service) rescoreReported() error {
+	reported := make(map[model.Chain]map[string]struct{})
+
+	err := s.store.ForEachReport(func(r *database.Report) error {
+		if reported[r.Chain] == nil {
+			reported[r.Chain] = make(map[string]struct{})
+		}
+		reported[r.Chain][model.NormalizeAddress(r.Chain, r.ScammerAddress)] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for chain, addresses := range reported {
+		for address := range addresses {
+			if err := s.rescore(chain, address); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *service) recomputeStatus(ctx *requestContext) (int, interface{}, error) {
+	state, err := s.store.GetRecomputeState()
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	return http.StatusOK, RecomputeStatusResponse{
+		Running: s.store.Recomputing(),
+		State:   state,
+	}, nil
+}
@@ -0,0 +1,251 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/log"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+// reportID returns the ID of a report given either as listed by all_scam_reports, or base64 encoded as returned by
+// post_scam_report.
+func reportID(id string) string {
+	if decoded, err := base64.StdEncoding.DecodeString(id); err == nil {
+		if _, err := uuid.FromString(string(decoded)); err == nil {
+			return string(decoded)
+		}
+	}
+	return id
+}
+
+// signedReportChange checks that req, a change to the version version of the report id, is signed by the report's
+// reporter accountID. signature points to the signature field of req, which isn't signed.
+func (s *service) signedReportChange(id string, accountID string, version int, req interface{}, signature *string) (*database.Report, int, error) {
+	if status, err := signedRequest(accountID, req, signature); err != nil {
+		return nil, status, err
+	}
+
+	r, status, err := s.getReport(id)
+	if err != nil {
+		return nil, status, err
+	}
+
+	if r.AccountID != accountID {
+		return nil, http.StatusForbidden, errors.New("only the reporter can change a report")
+	}
+
+	if r.Status == database.ReportRetracted {
+		return nil, http.StatusConflict, errors.New("report was retracted")
+	}
+
+	if r.Version != version {
+		return nil, http.StatusConflict, database.ErrReportChanged
+	}
+
+	return r, http.StatusOK, nil
+}
+
+// signedRequest checks that req is signed by the account accountID. signature points to the signature field of req,
+// which isn't signed.
+func signedRequest(accountID string, req interface{}, signature *string) (int, error) {
+	sig := *signature
+	*signature = ""
+	message, err := json.Marshal(req)
+	*signature = sig
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	if err := verifySignature(accountID, message, sig); err != nil {
+		return http.StatusUnauthorized, err
+	}
+
+	return http.StatusOK, nil
+}
+
+// getReport returns the report id, given as reportID accepts it.
+func (s *service) getReport(id string) (*database.Report, int, error) {
+	r, err := s.store.GetReport(reportID(id))
+	if err == leveldb.ErrNotFound {
+		return nil, http.StatusNotFound, errors.Errorf("report %s not found", id)
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return r, http.StatusOK, nil
+}
+
+func (s *service) editReport(ctx *requestContext) (int, interface{}, error) {
+	var req EditReportRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	r, status, err := s.signedReportChange(req.ReportID, req.AccountID, req.Version, &req, &req.Signature)
+	if err != nil {
+		return status, nil, err
+	}
+
+	r.Title = req.Title
+	r.Content = req.Content
+
+	err = s.store.UpdateReport(r, &database.ReportVersion{
+		Action:    database.ReportActionEdit,
+		Actor:     req.AccountID,
+		Signature: req.Signature,
+	})
+	if err != nil {
+		return reportChangeStatus(err), nil, err
+	}
+
+	return http.StatusOK, ReportChangeResponse{ID: r.ID, Version: r.Version, Status: string(r.Status)}, nil
+}
+
+func (s *service) retractReport(ctx *requestContext) (int, interface{}, error) {
+	var req RetractReportRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	r, status, err := s.signedReportChange(req.ReportID, req.AccountID, req.Version, &req, &req.Signature)
+	if err != nil {
+		return status, nil, err
+	}
+
+	r.Status = database.ReportRetracted
+
+	err = s.store.UpdateReport(r, &database.ReportVersion{
+		Action:    database.ReportActionRetract,
+		Actor:     req.AccountID,
+		Signature: req.Signature,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		return reportChangeStatus(err), nil, err
+	}
+
+	s.reportRemoved(r)
+
+	return http.StatusOK, ReportChangeResponse{ID: r.ID, Version: r.Version, Status: string(r.Status)}, nil
+}
+
+func (s *service) deleteReport(ctx *requestContext) (int, interface{}, error) {
+	var req DeleteReportRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	if req.Actor == "" {
+		req.Actor = defaultActor
+	}
+
+	r, err := s.store.DeleteReport(reportID(req.ReportID), req.Actor, req.Reason)
+	if err == leveldb.ErrNotFound {
+		return http.StatusNotFound, nil, errors.Errorf("report %s not found", req.ReportID)
+	}
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+
+	s.reportRemoved(r)
+
+	return http.StatusOK, ReportChangeResponse{ID: r.ID, Version: r.Version}, nil
+}
+
+// reportChangeStatus returns the status code of an error changing a report.
+func reportChangeStatus(err error) int {
+	switch err {
+	case database.ErrReportChanged:
+		return http.StatusConflict
+	case leveldb.ErrNotFound:
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// reportRemoved records the score of the target of the retracted or deleted report r, which drops at once, and
+// recomputes taint so that r stops tainting the addresses its target's funds reached.
+func (s *service) reportRemoved(r *database.Report) {
+	if err := s.rescore(r.Chain, r.ScammerAddress); err != nil {
+		log.Warn().Err(err).Str("report", r.ID).Msg("Failed to record the score of the reported address.")
+	}
+
+	s.scheduleRecompute()
+}
+
+// reportStatusChanged updates taint and the score of the target of the report r, moved from the status from, if the
+// active policy weighs its new status differently: a report that stops counting no longer taints anything, and one
+// that starts counting taints again the addresses its target's funds reached.
+func (s *service) reportStatusChanged(r *database.Report, from database.ReportStatus) {
+	p := s.policies.Policy()
+	counted, counts := p.ReportStatus[string(from)] > 0, p.ReportStatus[string(r.Status)] > 0
+
+	switch {
+	case counted && !counts:
+		s.reportRemoved(r)
+	case !counted && counts:
+		go func() {
+			if err := s.store.ApplyChanges(&database.ChangeSet{Chain: r.Chain, Sources: []string{r.ScammerAddress}}, p); err != nil {
+				log.Warn().Err(err).Str("report", r.ID).Msg("Failed to update taint from the reported address.")
+				return
+			}
+
+			if err := s.rescore(r.Chain, r.ScammerAddress); err != nil {
+				log.Warn().Err(err).Str("report", r.ID).Msg("Failed to record the score of the reported address.")
+			}
+		}()
+	default:
+		if err := s.rescore(r.Chain, r.ScammerAddress); err != nil {
+			log.Warn().Err(err).Str("report", r.ID).Msg("Failed to record the score of the reported address.")
+		}
+	}
+}
+
+func (s *service) reportVersions(ctx *requestContext) (int, interface{}, error) {
+	var req ReportVersionsRequest
+
+	if err := ctx.readJSON(&req); err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+
+	if err := validate.Struct(req); err != nil {
+		return http.StatusBadRequest, nil, errors.Wrap(err, "invalid request")
+	}
+
+	versions, err := s.store.GetReportVersions(reportID(req.ReportID))
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	if len(versions) == 0 {
+		return http.StatusNotFound, nil, errors.Errorf("report %s not found", req.ReportID)
+	}
+
+	for _, v := range versions {
+		v.Report.ScammerAddress = model.DisplayAddress(v.Report.Chain, v.Report.ScammerAddress)
+		v.Report.VictimAddress = model.DisplayAddress(v.Report.Chain, v.Report.VictimAddress)
+	}
+
+	return http.StatusOK, ReportVersionsResponse{Versions: versions}, nil
+}
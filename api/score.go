@@ -0,0 +1,194 @@
+package api
+
+import (
+	"time"
+
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/label"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/perlin-network/safu-go/policy"
+	"github.com/perlin-network/safu-go/score"
+	"github.com/perlin-network/safu-go/taint"
+)
+
+// reportScores is the aggregated score of the reports against an address's entity, and the reputation of their
+// reporters.
+type reportScores struct {
+	*score.Result
+
+	// reputation is the reputation of the reporters, every reporter counting once, decayed since their latest report.
+	reputation float64
+
+	// titles are the titles of the reports of a status the policy weighs.
+	titles []string
+
+	// disputes are the disputes of the reports.
+	disputes []*ReportDispute
+}
+
+// scoreReports scores the reports against the entity of address submitted by the unix timestamp asOf under the
+// policy p. Reports against any address of the entity count against all of them.
+func (s *service) scoreReports(chain model.Chain, address string, asOf int64, p *policy.Policy) (*reportScores, error) {
+	reports, err := s.store.GetReportsByEntity(chain, address)
+	if err != nil {
+		return nil, err
+	}
+
+	return aggregateReports(reports, asOf, p, s.ledger.GetRep)
+}
+
+// aggregateReports scores reports submitted by the unix timestamp asOf under the policy p, getting the reputation of
+// their reporters from getRep. The reporters of reports of a status p doesn't weigh don't add their reputation.
+func aggregateReports(reports []*database.Report, asOf int64, p *policy.Policy, getRep func(accountID string) (int, error)) (*reportScores, error) {
+	reputations := make(map[string]int)
+	var scored []*score.Report
+	var titles []string
+	disputes := []*ReportDispute{}
+	var latest int64
+
+	for _, r := range reports {
+		if r.ReportedAt() > asOf {
+			continue
+		}
+
+		if r.Dispute != nil {
+			disputes = append(disputes, &ReportDispute{
+				ReportID:   r.ID,
+				Status:     string(r.Dispute.Status),
+				OpenedAt:   r.Dispute.OpenedAt,
+				ResolvedAt: r.Dispute.ResolvedAt,
+			})
+		}
+
+		sr := &score.Report{
+			ID:        r.ID,
+			Reporter:  r.AccountID,
+			Taint:     r.Taint,
+			Timestamp: r.ReportedAt(),
+			Status:    string(r.Status),
+		}
+		scored = append(scored, sr)
+
+		// the report still shows among the contributions, with no weight
+		if p.ReportStatus[string(r.Status)] <= 0 {
+			continue
+		}
+
+		if _, ok := reputations[r.AccountID]; !ok {
+			rep, err := getRep(r.AccountID)
+			if err != nil {
+				return nil, err
+			}
+			reputations[r.AccountID] = rep
+		}
+		sr.Reputation = reputations[r.AccountID]
+
+		if r.ReportedAt() > latest {
+			latest = r.ReportedAt()
+		}
+
+		titles = append(titles, r.Title)
+	}
+
+	var rep int
+	for _, r := range reputations {
+		rep += r
+	}
+
+	return &reportScores{
+		Result:     score.Aggregate(scored, asOf, p.Weights()),
+		reputation: float64(rep) * taint.DecayFactor(latest, asOf, time.Duration(p.HalfLife)),
+		titles:     titles,
+		disputes:   disputes,
+	}, nil
+}
+
+// addressScore is the taint score of an address, with what it was computed from.
+type addressScore struct {
+	*policy.Breakdown
+
+	reports   *reportScores
+	risk      *database.GraphRisk
+	allowed   *database.AllowlistEntry
+	sanctions []string
+
+	// band is the risk band of the score, and categories the categories of the address.
+	band       string
+	categories []string
+}
+
+// scoreAddress scores address, holding labels, as of the unix timestamp asOf under the policy p.
+func (s *service) scoreAddress(chain model.Chain, address string, asOf int64, p *policy.Policy, labels []*label.Label) (*addressScore, error) {
+	reports, err := s.scoreReports(chain, address, asOf, p)
+	if err != nil {
+		return nil, err
+	}
+
+	risk, err := s.store.GetGraphRisk(chain, address)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.store.GetAllowlistEntry(chain, address)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &addressScore{
+		reports:   reports,
+		risk:      risk,
+		allowed:   allowed,
+		sanctions: label.Sanctioned(labels),
+	}
+
+	in := policy.Inputs{
+		Reports:     reports.Score,
+		Reputation:  reports.reputation,
+		Sanctioned:  len(res.sanctions) > 0,
+		Allowlisted: allowed != nil,
+	}
+	if risk != nil {
+		in.GraphRisk = risk.Score
+	}
+
+	res.Breakdown = p.Score(in)
+	res.band = p.Band(res.Score)
+	res.categories = p.Categorize(reports.titles, labels)
+
+	return res, nil
+}
+
+// snapshot records the score of address, scored at the unix timestamp now under the policy p, in its score history.
+func (s *service) snapshot(chain model.Chain, address string, now int64, p *policy.Policy, scored *addressScore) error {
+	return s.store.PutScoreSnapshot(&database.ScoreSnapshot{
+		Chain:         chain,
+		Address:       address,
+		Time:          now,
+		PolicyVersion: p.Version,
+		Score:         scored.Score,
+		RiskBand:      scored.band,
+		Reports:       scored.Reports,
+		Reputation:    scored.Reputation,
+		GraphRisk:     scored.GraphRisk,
+		Sanctioned:    len(scored.sanctions) > 0,
+		Allowlisted:   scored.allowed != nil,
+	})
+}
+
+// rescore scores address now under the active policy, and records the score in its score history.
+func (s *service) rescore(chain model.Chain, address string) error {
+	now := time.Now().Unix()
+	p := s.policies.Policy()
+
+	labels, err := s.store.GetLabels(chain, address)
+	if err != nil {
+		return err
+	}
+
+	scored, err := s.scoreAddress(chain, address, now, p, labels)
+	if err != nil {
+		return err
+	}
+
+	return s.snapshot(chain, address, now, p, scored)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/policy"
+)
+
+func TestAggregateReportsIgnoresUncountedReports(t *testing.T) {
+	const asOf = int64(1000000)
+
+	p := policy.Default()
+
+	reputations := map[string]int{"alice": 3, "bob": -2, "carol": 50}
+
+	base := []*database.Report{
+		{ID: "1", AccountID: "alice", Taint: 60, Timestamp: asOf - 3600, Status: database.ReportSubmitted},
+		{ID: "2", AccountID: "bob", Taint: 30, Timestamp: asOf - 7200, Status: database.ReportVerified},
+	}
+
+	for _, status := range []database.ReportStatus{database.ReportRetracted, database.ReportRejected} {
+		t.Run(string(status), func(t *testing.T) {
+			var asked []string
+			getRep := func(accountID string) (int, error) {
+				asked = append(asked, accountID)
+				return reputations[accountID], nil
+			}
+
+			want, err := aggregateReports(base, asOf, p, getRep)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// a reputable new reporter, and a counted reporter, whose reports don't count
+			reports := append([]*database.Report{
+				{ID: "3", AccountID: "carol", Taint: 100, Timestamp: asOf, Status: status},
+				{ID: "4", AccountID: "alice", Taint: 100, Timestamp: asOf, Status: status},
+			}, base...)
+
+			asked = nil
+			got, err := aggregateReports(reports, asOf, p, getRep)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got.Score != want.Score {
+				t.Errorf("Score = %v, want %v", got.Score, want.Score)
+			}
+			if got.reputation != want.reputation {
+				t.Errorf("reputation = %v, want %v", got.reputation, want.reputation)
+			}
+			if len(got.titles) != len(want.titles) {
+				t.Errorf("titles = %v, want %v", got.titles, want.titles)
+			}
+
+			for _, accountID := range asked {
+				if accountID == "carol" {
+					t.Errorf("the reputation of a reporter whose only report is %s was fetched", status)
+				}
+			}
+
+			if len(got.Contributions) != len(reports) {
+				t.Errorf("%d contributions, want %d", len(got.Contributions), len(reports))
+			}
+			for _, c := range got.Contributions {
+				if c.Status == string(status) && (c.Weight != 0 || c.Counted && c.Taint != 0) {
+					t.Errorf("%s report %s weighs %v with taint %v, want 0", status, c.ReportID, c.Weight, c.Taint)
+				}
+			}
+		})
+	}
+}
+
+// safu_cli timestamps its reports in nanoseconds.
+func TestAggregateReportsNanosecondTimestamps(t *testing.T) {
+	asOf := time.Now().Unix()
+	p := policy.Default()
+
+	getRep := func(accountID string) (int, error) {
+		return 0, nil
+	}
+
+	seconds := []*database.Report{{ID: "1", AccountID: "alice", Taint: 60, Timestamp: asOf - 60, Status: database.ReportSubmitted}}
+	nanoseconds := []*database.Report{{ID: "1", AccountID: "alice", Taint: 60, Timestamp: time.Unix(asOf-60, 0).UnixNano(), Status: database.ReportSubmitted}}
+
+	want, err := aggregateReports(seconds, asOf, p, getRep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := aggregateReports(nanoseconds, asOf, p, getRep)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Score == 0 || got.Score != want.Score {
+		t.Errorf("Score of a report timestamped in nanoseconds = %v, want %v", got.Score, want.Score)
+	}
+	if len(got.Contributions) != 1 || got.Contributions[0].Weight != want.Contributions[0].Weight {
+		t.Errorf("contributions of a report timestamped in nanoseconds = %+v, want %+v", got.Contributions, want.Contributions)
+	}
+}
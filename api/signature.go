@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/perlin-network/noise/crypto"
+	"github.com/perlin-network/noise/crypto/blake2b"
+	"github.com/perlin-network/noise/crypto/ed25519"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+)
+
+// Reporters sign their requests with the ed25519 key of their Wavelet account, as safu_cli does.
+var (
+	signaturePolicy = ed25519.New()
+	hashPolicy      = blake2b.New()
+)
+
+// verifySignature checks that signature is the base64 encoded signature of the blake2b hash of message by the
+// account accountID, the hex encoded public key of a Wavelet account.
+func verifySignature(accountID string, message []byte, signature string) error {
+	publicKey, err := hex.DecodeString(accountID)
+	if err != nil {
+		return errors.Wrap(err, "invalid account_id")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return errors.Wrap(err, "invalid signature")
+	}
+
+	if !crypto.Verify(signaturePolicy, hashPolicy, publicKey, message, sig) {
+		return errors.New("invalid signature")
+	}
+
+	return nil
+}
+
+// recoverPersonalSign returns the Ethereum address whose key signed message, as personal_sign does: signature is the
+// hex encoded 65 byte signature, R, S then V, of the keccak256 hash of message prefixed with
+// "\x19Ethereum Signed Message:\n" and its length.
+func recoverPersonalSign(message []byte, signature string) (string, error) {
+	sig, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		return "", errors.Wrap(err, "invalid signature")
+	}
+	if len(sig) != 65 {
+		return "", errors.Errorf("invalid signature: %d bytes long instead of 65", len(sig))
+	}
+
+	// wallets set V to 27 or 28, some to 0 or 1
+	v := sig[64]
+	if v >= 27 {
+		v -= 27
+	}
+	if v > 1 {
+		return "", errors.Errorf("invalid signature: recovery id %d", sig[64])
+	}
+
+	// the compact signatures of secp256k1 put the recovery code first
+	compact := append([]byte{27 + v}, sig[:64]...)
+
+	hash := keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))), message)
+
+	publicKey, _, err := ecdsa.RecoverCompact(compact, hash)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid signature")
+	}
+
+	return "0x" + hex.EncodeToString(keccak256(publicKey.SerializeUncompressed()[1:])[12:]), nil
+}
+
+func keccak256(data ...[]byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	for _, b := range data {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	"github.com/perlin-network/noise/crypto/ed25519"
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/model"
+)
+
+// testKey is the private key of the web3.js documentation's examples, and testKeyAddress its address.
+const (
+	testKey        = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	testKeyAddress = "0x2c7536E3605D9C16a7a3D7b1898e529396a65c23"
+)
+
+// personalSign signs message with the hex encoded private key key as personal_sign does.
+func personalSign(t *testing.T, key string, message string) string {
+	t.Helper()
+
+	b, err := hex.DecodeString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d", len(message))), []byte(message))
+
+	// the compact signatures of secp256k1 put the recovery code first, and personal_sign last
+	compact := ecdsa.SignCompact(secp256k1.PrivKeyFromBytes(b), hash, false)
+
+	return "0x" + hex.EncodeToString(append(compact[1:], compact[0]))
+}
+
+func TestRecoverPersonalSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		signature string
+		want      string
+		err       bool
+	}{
+		{
+			name:      "web3.js vector",
+			message:   "Some data",
+			signature: "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c",
+			want:      testKeyAddress,
+		},
+		{
+			name:      "recovery id 0 or 1",
+			message:   "Some data",
+			signature: "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a02901",
+			want:      testKeyAddress,
+		},
+		{
+			name:      "another message",
+			message:   "Some other data",
+			signature: "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c",
+		},
+		{
+			name:      "no prefix",
+			message:   "Some data",
+			signature: "b91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291c",
+			want:      testKeyAddress,
+		},
+		{name: "too short", message: "Some data", signature: "0xb91467e570a6466aa9e9876cbcd013ba", err: true},
+		{name: "not hex", message: "Some data", signature: "0xzz", err: true},
+		{
+			name:      "bad recovery id",
+			message:   "Some data",
+			signature: "0xb91467e570a6466aa9e9876cbcd013baba02900b8979d43fe208a4a4f339f5fd6007e74cd82e037b800186422fc2da167c747ef045e5d18a5f5d4300f8e1a0291f",
+			err:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := recoverPersonalSign([]byte(tt.message), tt.signature)
+			if tt.err {
+				if err == nil {
+					t.Fatalf("recoverPersonalSign() = %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// a signature of another message recovers some other address
+			if tt.want == "" {
+				if strings.EqualFold(got, testKeyAddress) {
+					t.Errorf("recoverPersonalSign() = %s, the signer of another message", got)
+				}
+				return
+			}
+
+			if !strings.EqualFold(got, tt.want) {
+				t.Errorf("recoverPersonalSign() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisputeChallenge(t *testing.T) {
+	r := &database.Report{ID: "report", Chain: model.ChainEthereum, ScammerAddress: strings.ToLower(testKeyAddress), Version: 2}
+	signature := personalSign(t, testKey, disputeChallenge(r, "the funds were mine"))
+
+	stale := *r
+	stale.Version = 1
+
+	tests := []struct {
+		name     string
+		report   *database.Report
+		evidence string
+		valid    bool
+	}{
+		{"signed challenge", r, "the funds were mine", true},
+		{"stale version", &stale, "the funds were mine", false},
+		{"other evidence", r, "the funds were someone else's", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer, err := recoverPersonalSign([]byte(disputeChallenge(tt.report, tt.evidence)), signature)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if valid := model.NormalizeAddress(r.Chain, signer) == model.NormalizeAddress(r.Chain, r.ScammerAddress); valid != tt.valid {
+				t.Errorf("signature of the challenge recovered %s, holder of the reported address %t, want %t", signer, valid, tt.valid)
+			}
+		})
+	}
+
+	// another key signs the same challenge
+	other := personalSign(t, strings.Repeat("11", 32), disputeChallenge(r, "the funds were mine"))
+
+	signer, err := recoverPersonalSign([]byte(disputeChallenge(r, "the funds were mine")), other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model.NormalizeAddress(r.Chain, signer) == model.NormalizeAddress(r.Chain, r.ScammerAddress) {
+		t.Errorf("signature by another key recovered the reported address %s", signer)
+	}
+}
+
+// signReport signs req as safu_cli does: the signature of the request with an empty proof, by the reporter's key.
+func signReport(t *testing.T, req *SubmitReportRequest) {
+	t.Helper()
+
+	keys := ed25519.RandomKeyPair()
+	req.AccountID = keys.PublicKeyHex()
+	req.Proof = ""
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := keys.Sign(signaturePolicy, hashPolicy, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Proof = base64.StdEncoding.EncodeToString(sig)
+}
+
+func TestSubmitReportSignature(t *testing.T) {
+	s := newTestService(t)
+
+	signed := SubmitReportRequest{
+		Timestamp:      1546300800,
+		ScammerAddress: "0x" + strings.Repeat("ab", 20),
+		VictimAddress:  "0x" + strings.Repeat("cd", 20),
+		Title:          "title",
+		Content:        "content",
+	}
+	signReport(t, &signed)
+
+	if status, err := signedRequest(signed.AccountID, &signed, &signed.Proof); err != nil {
+		t.Fatalf("signedRequest() of a signed report = %d, %v", status, err)
+	}
+
+	unsigned := signed
+	unsigned.Proof = ""
+
+	tampered := signed
+	tampered.ScammerAddress = "0x" + strings.Repeat("ef", 20)
+
+	other := signed
+	signReport(t, &other)
+	other.AccountID = signed.AccountID
+
+	tests := []struct {
+		name   string
+		req    SubmitReportRequest
+		status int
+	}{
+		{"unsigned", unsigned, http.StatusBadRequest},
+		{"not base64", func() SubmitReportRequest { r := signed; r.Proof = "!"; return r }(), http.StatusUnauthorized},
+		{"tampered", tampered, http.StatusUnauthorized},
+		{"signed by another account", other, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _, err := call(t, s, s.postScamReport, tt.req)
+			if err == nil || status != tt.status {
+				t.Errorf("postScamReport() = %d, %v, want %d", status, err, tt.status)
+			}
+		})
+	}
+
+	err := s.store.ForEachReport(func(r *database.Report) error {
+		t.Errorf("rejected report %s stored", r.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the proof of an accepted report signs its submission
+	id, err := s.store.AddReport(database.Report{
+		Timestamp:      signed.Timestamp,
+		AccountID:      signed.AccountID,
+		ScammerAddress: signed.ScammerAddress,
+		VictimAddress:  signed.VictimAddress,
+		Title:          signed.Title,
+		Content:        signed.Content,
+		Proof:          signed.Proof,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err := s.store.GetReportVersions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || versions[0].Signature != signed.Proof {
+		t.Errorf("submission versions = %+v, want one signed by %s", versions, signed.Proof)
+	}
+}
@@ -0,0 +1,112 @@
+package bitcoin
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Block is a block as returned by Bitcoin Core's getblock with verbosity 2 or 3.
+type Block struct {
+	Hash   string `json:"hash"`
+	Height uint64 `json:"height"`
+	Time   int64  `json:"time"`
+	Tx     []*Tx  `json:"tx"`
+}
+
+// Tx is a decoded transaction.
+type Tx struct {
+	TxID string  `json:"txid"`
+	Vin  []*Vin  `json:"vin"`
+	Vout []*Vout `json:"vout"`
+}
+
+// Vin is a transaction input.
+type Vin struct {
+	Coinbase string `json:"coinbase"`
+	TxID     string `json:"txid"`
+	Vout     uint32 `json:"vout"`
+
+	// Prevout is the output spent, included by getblock with verbosity 3.
+	Prevout *Vout `json:"prevout"`
+}
+
+// Vout is a transaction output.
+type Vout struct {
+	Value        json.Number `json:"value"`
+	N            uint32      `json:"n"`
+	ScriptPubKey struct {
+		Address string `json:"address"`
+
+		// Addresses is reported instead of Address by Bitcoin Core before v22.
+		Addresses []string `json:"addresses"`
+	} `json:"scriptPubKey"`
+}
+
+// Address returns the address an output pays to, or an empty string for non-standard and data outputs.
+func (v *Vout) Address() string {
+	if v.ScriptPubKey.Address != "" {
+		return v.ScriptPubKey.Address
+	}
+
+	// bare multisig outputs have no single owner
+	if len(v.ScriptPubKey.Addresses) == 1 {
+		return v.ScriptPubKey.Addresses[0]
+	}
+
+	return ""
+}
+
+// Satoshis returns the value of the output in satoshis.
+func (v *Vout) Satoshis() (int64, error) {
+	value, ok := new(big.Rat).SetString(v.Value.String())
+	if !ok {
+		return 0, errors.Errorf("invalid output value %q", v.Value)
+	}
+
+	sats := value.Mul(value, big.NewRat(100000000, 1))
+	if !sats.IsInt() {
+		return 0, errors.Errorf("output value %q has more than 8 decimals", v.Value)
+	}
+
+	return sats.Num().Int64(), nil
+}
+
+// LoadBlockFixtures reads the blocks stored in the JSON files of dir, each holding the output of getblock with
+// verbosity 2 or 3, and returns them ordered by height.
+func LoadBlockFixtures(dir string) ([]*Block, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*Block
+
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read block fixture %s", file)
+		}
+
+		var block Block
+		if err := json.Unmarshal(b, &block); err != nil {
+			return nil, errors.Wrapf(err, "malformed block fixture %s", file)
+		}
+
+		blocks = append(blocks, &block)
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].Height != blocks[j].Height {
+			return blocks[i].Height < blocks[j].Height
+		}
+		return strings.Compare(blocks[i].Hash, blocks[j].Hash) < 0
+	})
+
+	return blocks, nil
+}
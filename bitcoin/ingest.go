@@ -0,0 +1,179 @@
+package bitcoin
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/perlin-network/safu-go/cluster"
+	"github.com/perlin-network/safu-go/model"
+)
+
+// coinJoinOutputs is the number of equal valued outputs above which a transaction with several input owners is
+// assumed to be a CoinJoin, whose inputs must not be clustered together.
+const coinJoinOutputs = 3
+
+type output struct {
+	address string
+	value   int64
+}
+
+// TxLink is an edge of the transaction graph: an output of one transaction spent by another.
+type TxLink struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Vout  uint32 `json:"vout"`
+	Value int64  `json:"value"`
+}
+
+// Ingester builds the address and transaction graphs of a sequence of blocks. Blocks must be added in order, so
+// that the outputs spent by a transaction are known when the transaction is added.
+type Ingester struct {
+	utxos    map[string]output
+	clusters *cluster.Clusterer
+	links    []*TxLink
+}
+
+func NewIngester() *Ingester {
+	return &Ingester{
+		utxos:    make(map[string]output),
+		clusters: cluster.New(),
+	}
+}
+
+func outpoint(txID string, n uint32) string {
+	return fmt.Sprintf("%s:%d", txID, n)
+}
+
+// AddBlock adds the transactions of a block to the graphs, and returns the edges of the address graph they create.
+// Each edge moves the share of an output funded by one of the input addresses, i.e. a transaction spending 1 BTC
+// from A and 3 BTC from B to pay 2 BTC to C creates the edges A -> C of 0.5 BTC and B -> C of 1.5 BTC.
+func (in *Ingester) AddBlock(b *Block) ([]*model.Edge, error) {
+	var edges []*model.Edge
+
+	for _, tx := range b.Tx {
+		inputs := make(map[string]int64)
+		var totalIn int64
+
+		for _, vin := range tx.Vin {
+			if vin.Coinbase != "" {
+				continue
+			}
+
+			key := outpoint(vin.TxID, vin.Vout)
+
+			spent, ok := in.utxos[key]
+			if ok {
+				delete(in.utxos, key)
+			} else if vin.Prevout != nil {
+				value, err := vin.Prevout.Satoshis()
+				if err != nil {
+					return nil, err
+				}
+				spent = output{address: vin.Prevout.Address(), value: value}
+			} else {
+				// spends an output of a block that wasn't ingested
+				continue
+			}
+
+			in.links = append(in.links, &TxLink{From: vin.TxID, To: tx.TxID, Vout: vin.Vout, Value: spent.value})
+
+			if spent.address == "" {
+				continue
+			}
+
+			inputs[spent.address] += spent.value
+			totalIn += spent.value
+		}
+
+		outputs := make(map[string]int64)
+		values := make(map[int64]int)
+
+		for _, vout := range tx.Vout {
+			value, err := vout.Satoshis()
+			if err != nil {
+				return nil, err
+			}
+
+			address := vout.Address()
+			in.utxos[outpoint(tx.TxID, vout.N)] = output{address: address, value: value}
+
+			if address == "" {
+				continue
+			}
+
+			outputs[address] += value
+			values[value]++
+		}
+
+		in.cluster(tx.TxID, inputs, values)
+
+		if totalIn == 0 {
+			continue
+		}
+
+		for _, from := range sortedKeys(inputs) {
+			for _, to := range sortedKeys(outputs) {
+				// change returned to an input address isn't a transfer
+				if from == to {
+					continue
+				}
+
+				share := new(big.Int).Mul(big.NewInt(outputs[to]), big.NewInt(inputs[from]))
+				share.Quo(share, big.NewInt(totalIn))
+
+				edge := model.NewEdge(model.ChainBitcoin, from, to, tx.TxID, "")
+				edge.BlockNumber = b.Height
+				edge.Timestamp = b.Time
+				edge.Amount = share.String()
+
+				edges = append(edges, edge)
+			}
+		}
+	}
+
+	return edges, nil
+}
+
+// cluster applies the common-input-ownership heuristic: every address spent from by the same transaction is
+// controlled by the same entity. CoinJoins, whose many equal outputs are paid for by different entities, are skipped.
+func (in *Ingester) cluster(txID string, inputs map[string]int64, outputValues map[int64]int) {
+	if len(inputs) < 2 {
+		return
+	}
+
+	for _, count := range outputValues {
+		if count >= coinJoinOutputs {
+			return
+		}
+	}
+
+	addresses := sortedKeys(inputs)
+	for _, address := range addresses[1:] {
+		in.clusters.Link(&cluster.Evidence{
+			Heuristic: cluster.HeuristicCommonInput,
+			Addresses: [2]string{addresses[0], address},
+			TxHashes:  []string{txID},
+		})
+	}
+}
+
+// Clusters returns the clusters of addresses spent together, with the transactions that spent them as evidence.
+func (in *Ingester) Clusters() []*cluster.Cluster {
+	return in.clusters.Clusters()
+}
+
+// TxLinks returns the edges of the transaction graph built so far.
+func (in *Ingester) TxLinks() []*TxLink {
+	return in.links
+}
+
+func sortedKeys(m map[string]int64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
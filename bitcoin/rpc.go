@@ -0,0 +1,93 @@
+package bitcoin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RPC is a client of a Bitcoin Core node's JSON-RPC API.
+type RPC struct {
+	url      string
+	user     string
+	password string
+	client   *http.Client
+}
+
+func NewRPC(url string, user string, password string) *RPC {
+	return &RPC{
+		url:      url,
+		user:     user,
+		password: password,
+		client:   &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+func (r *RPC) call(method string, params []interface{}, out interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "1.0",
+		"id":      "safu",
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	if r.user != "" {
+		req.SetBasicAuth(r.user, r.password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "%s failed", method)
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return errors.Wrapf(err, "%s returned a malformed response (status %s)", method, resp.Status)
+	}
+
+	if res.Error != nil {
+		return errors.Errorf("%s failed: %s (code %d)", method, res.Error.Message, res.Error.Code)
+	}
+
+	return json.Unmarshal(res.Result, out)
+}
+
+// BlockAt fetches the block at height with its decoded transactions. Spent outputs are included on nodes that
+// support getblock with verbosity 3.
+func (r *RPC) BlockAt(height uint64) (*Block, error) {
+	var hash string
+	if err := r.call("getblockhash", []interface{}{height}, &hash); err != nil {
+		return nil, err
+	}
+
+	var block Block
+	if err := r.call("getblock", []interface{}{hash, 3}, &block); err == nil {
+		return &block, nil
+	}
+
+	// nodes older than v25 only support up to verbosity 2
+	if err := r.call("getblock", []interface{}{hash, 2}, &block); err != nil {
+		return nil, err
+	}
+
+	return &block, nil
+}
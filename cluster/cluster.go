@@ -0,0 +1,150 @@
+package cluster
+
+import (
+	"sort"
+	"strings"
+)
+
+// Heuristic is a rule that infers that several addresses are controlled by the same entity.
+type Heuristic string
+
+const (
+	// HeuristicCommonInput links the addresses spent from by the same UTXO transaction.
+	HeuristicCommonInput Heuristic = "common_input"
+	// HeuristicDepositReuse links the addresses that send funds to the same exchange deposit address.
+	HeuristicDepositReuse Heuristic = "deposit_reuse"
+	// HeuristicSharedFunding links the fresh addresses funded by the same address.
+	HeuristicSharedFunding Heuristic = "shared_funding"
+)
+
+// Evidence is the reason two addresses were assigned to the same cluster.
+type Evidence struct {
+	Heuristic Heuristic `json:"heuristic"`
+	Addresses [2]string `json:"addresses"`
+	TxHashes  []string  `json:"tx_hashes"`
+
+	// Via is the address the heuristic linked the two addresses through, i.e. the deposit address they both sent
+	// funds to, or the address that funded both.
+	Via string `json:"via,omitempty"`
+}
+
+func (e *Evidence) key() string {
+	return strings.Join([]string{string(e.Heuristic), e.Addresses[0], e.Addresses[1], e.Via, strings.Join(e.TxHashes, ",")}, "|")
+}
+
+// Cluster is a set of addresses controlled by the same entity, identified by its smallest address.
+type Cluster struct {
+	ID       string      `json:"id"`
+	Members  []string    `json:"members"`
+	Evidence []*Evidence `json:"evidence"`
+}
+
+// Clusterer merges addresses into clusters as evidence links them.
+type Clusterer struct {
+	parents  map[string]string
+	evidence map[string]*Evidence
+}
+
+func New() *Clusterer {
+	return &Clusterer{
+		parents:  make(map[string]string),
+		evidence: make(map[string]*Evidence),
+	}
+}
+
+// Link merges the clusters of the two addresses of ev.
+func (c *Clusterer) Link(ev *Evidence) {
+	if _, ok := c.evidence[ev.key()]; ok {
+		return
+	}
+	c.evidence[ev.key()] = ev
+
+	ra, rb := c.find(ev.Addresses[0]), c.find(ev.Addresses[1])
+	if ra == rb {
+		return
+	}
+
+	// the smallest address names the cluster, so that IDs don't depend on the order evidence is found in
+	if rb < ra {
+		ra, rb = rb, ra
+	}
+	c.parents[rb] = ra
+}
+
+// Add merges the clusters of the members of every cluster in clusters, replaying their evidence.
+func (c *Clusterer) Add(clusters ...*Cluster) {
+	for _, cl := range clusters {
+		for _, ev := range cl.Evidence {
+			c.Link(ev)
+		}
+	}
+}
+
+func (c *Clusterer) find(address string) string {
+	parent, ok := c.parents[address]
+	if !ok || parent == address {
+		c.parents[address] = address
+		return address
+	}
+
+	root := c.find(parent)
+	c.parents[address] = root
+
+	return root
+}
+
+// Clusters returns the clusters of two or more addresses found so far, sorted by ID, with their members sorted.
+func (c *Clusterer) Clusters() []*Cluster {
+	clusters := make(map[string]*Cluster)
+
+	for address := range c.parents {
+		root := c.find(address)
+
+		cl, ok := clusters[root]
+		if !ok {
+			cl = &Cluster{ID: root}
+			clusters[root] = cl
+		}
+		cl.Members = append(cl.Members, address)
+	}
+
+	keys := make([]string, 0, len(c.evidence))
+	for key := range c.evidence {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		ev := c.evidence[key]
+		cl := clusters[c.find(ev.Addresses[0])]
+		cl.Evidence = append(cl.Evidence, ev)
+	}
+
+	var list []*Cluster
+	for _, cl := range clusters {
+		if len(cl.Members) < 2 {
+			continue
+		}
+
+		sort.Strings(cl.Members)
+		list = append(list, cl)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID < list[j].ID
+	})
+
+	return list
+}
+
+// EvidenceFor returns the evidence of the cluster that involves address.
+func (cl *Cluster) EvidenceFor(address string) []*Evidence {
+	var evidence []*Evidence
+	for _, ev := range cl.Evidence {
+		if ev.Addresses[0] == address || ev.Addresses[1] == address {
+			evidence = append(evidence, ev)
+		}
+	}
+
+	return evidence
+}
@@ -0,0 +1,121 @@
+package cluster
+
+import (
+	"github.com/perlin-network/safu-go/model"
+)
+
+const (
+	// MinHotWalletSenders is the number of distinct addresses an address must receive funds from to be deemed an
+	// exchange hot wallet, which deposit addresses sweep their funds to.
+	MinHotWalletSenders = 10
+
+	// MaxFundingFanout is the number of distinct addresses an address may send funds to and still be deemed to fund
+	// addresses of its own entity. Exchanges and faucets fund many unrelated addresses.
+	MaxFundingFanout = 20
+)
+
+// DepositReuse links the addresses that send funds to the same exchange deposit address. Exchanges assign every
+// customer their own deposit address, which only ever sweeps its funds to the exchange's hot wallet; the addresses
+// paying into it therefore belong to the same customer.
+func DepositReuse(c *Clusterer, g *model.Graph) {
+	for _, deposit := range g.Addresses() {
+		hotWallet, ok := sweptTo(g, deposit)
+		if !ok {
+			continue
+		}
+
+		var first string
+		var firstTx string
+
+		for _, sender := range g.Predecessors(deposit) {
+			if sender == hotWallet || sender == deposit {
+				continue
+			}
+
+			tx := g.EdgesBetween(sender, deposit)[0].TxHash
+
+			if first == "" {
+				first, firstTx = sender, tx
+				continue
+			}
+
+			c.Link(&Evidence{
+				Heuristic: HeuristicDepositReuse,
+				Addresses: [2]string{first, sender},
+				TxHashes:  []string{firstTx, tx},
+				Via:       deposit,
+			})
+		}
+	}
+}
+
+// SharedFunding links the addresses that first received funds, in the chain's native asset, from the same address.
+// Fresh addresses must be funded to pay for gas, and are usually funded by an address of the same entity. Funders
+// sending funds to more than MaxFundingFanout addresses are skipped, and so are deposit addresses, which belong to
+// the exchange rather than to their funder.
+func SharedFunding(c *Clusterer, g *model.Graph) {
+	funded := make(map[string][]*model.Edge)
+
+	for _, address := range g.Addresses() {
+		if _, ok := sweptTo(g, address); ok {
+			continue
+		}
+
+		var funding *model.Edge
+
+		for _, e := range g.InEdges(address) {
+			if e.Token != nil || e.From == address {
+				continue
+			}
+
+			if funding == nil || fundedBefore(e, funding) {
+				funding = e
+			}
+		}
+
+		if funding != nil {
+			funded[funding.From] = append(funded[funding.From], funding)
+		}
+	}
+
+	for _, funder := range g.Addresses() {
+		edges := funded[funder]
+		if len(edges) < 2 || len(g.Successors(funder)) > MaxFundingFanout {
+			continue
+		}
+
+		for _, e := range edges[1:] {
+			c.Link(&Evidence{
+				Heuristic: HeuristicSharedFunding,
+				Addresses: [2]string{edges[0].To, e.To},
+				TxHashes:  []string{edges[0].TxHash, e.TxHash},
+				Via:       funder,
+			})
+		}
+	}
+}
+
+// sweptTo returns the hot wallet address sends all its funds to, if address looks like an exchange deposit address.
+func sweptTo(g *model.Graph, address string) (string, bool) {
+	successors := g.Successors(address)
+	if len(successors) != 1 {
+		return "", false
+	}
+
+	hotWallet := successors[0]
+	if hotWallet == address || len(g.Predecessors(hotWallet)) < MinHotWalletSenders {
+		return "", false
+	}
+
+	return hotWallet, true
+}
+
+func fundedBefore(a *model.Edge, b *model.Edge) bool {
+	if a.Timestamp != b.Timestamp {
+		return a.Timestamp < b.Timestamp
+	}
+	if a.BlockNumber != b.BlockNumber {
+		return a.BlockNumber < b.BlockNumber
+	}
+	return a.Key() < b.Key()
+}
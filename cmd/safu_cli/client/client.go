@@ -114,11 +114,12 @@ func (client *Client) Deposit(depositAmount int64) (interface{}, error) {
 	}`, contractAddress, depositAmount))
 }
 
-func (client *Client) Query(targetAddress string) (interface{}, error) {
+func (client *Client) Query(targetAddress string, chain string) (interface{}, error) {
 	body := api.QueryAddressRequest{
 		AccountID:     client.config.AccountID,
 		Timestamp:     time.Now().UnixNano(),
 		TargetAddress: targetAddress,
+		Chain:         chain,
 	}
 	var taintResp api.QueryAddressResponse
 	if err := client.callTaintServer(api.RouteQueryAddress, body, &taintResp); err != nil {
@@ -127,7 +128,30 @@ func (client *Client) Query(targetAddress string) (interface{}, error) {
 	return taintResp, nil
 }
 
-func (client *Client) RegisterScamReport(scammerAddress string, victimAddress string, title string, content string) (interface{}, error) {
+// Path finds up to k paths funds may have taken from one address to another, crawling up to maxDepth hops from the
+// sender first if crawl is set.
+func (client *Client) Path(from string, to string, chain string, k int, maxDepth int, crawl bool) (interface{}, error) {
+	body := api.PathRequest{
+		Chain:    chain,
+		From:     from,
+		To:       to,
+		K:        k,
+		Crawl:    crawl,
+		MaxDepth: maxDepth,
+	}
+	var pathResp api.PathResponse
+	if err := client.callTaintServer(api.RoutePath, body, &pathResp); err != nil {
+		return nil, err
+	}
+	return pathResp, nil
+}
+
+// ExportGraph exports the neighborhood of an address described by req in a graph file format.
+func (client *Client) ExportGraph(req api.ExportGraphRequest) ([]byte, error) {
+	return client.requestTaintServer(api.RouteExportGraph, req)
+}
+
+func (client *Client) RegisterScamReport(scammerAddress string, victimAddress string, title string, content string, chain string, direction string) (interface{}, error) {
 	var err error
 	// 1. push the scam report to taint server
 	body := api.SubmitReportRequest{
@@ -137,6 +161,8 @@ func (client *Client) RegisterScamReport(scammerAddress string, victimAddress st
 		VictimAddress:  victimAddress,
 		Title:          title,
 		Content:        content,
+		Chain:          chain,
+		Direction:      direction,
 	}
 
 	keyPair, err := getKeyPair(client.config.PrivateKeyFile)
@@ -191,6 +217,94 @@ func (client *Client) RegisterScamReport(scammerAddress string, victimAddress st
 	}, nil
 }
 
+// EditScamReport replaces the title and content of the version version of a report of the client's account.
+func (client *Client) EditScamReport(reportID string, version int, title string, content string) (interface{}, error) {
+	body := api.EditReportRequest{
+		ReportID:  reportID,
+		AccountID: client.config.AccountID,
+		Version:   version,
+		Title:     title,
+		Content:   content,
+	}
+
+	sig, err := client.sign(body)
+	if err != nil {
+		return nil, err
+	}
+	body.Signature = sig
+
+	var taintResp api.ReportChangeResponse
+	if err := client.callTaintServer(api.RouteEditReport, body, &taintResp); err != nil {
+		return nil, err
+	}
+	return taintResp, nil
+}
+
+// RetractScamReport takes back the version version of a report of the client's account.
+func (client *Client) RetractScamReport(reportID string, version int, reason string) (interface{}, error) {
+	body := api.RetractReportRequest{
+		ReportID:  reportID,
+		AccountID: client.config.AccountID,
+		Version:   version,
+		Reason:    reason,
+	}
+
+	sig, err := client.sign(body)
+	if err != nil {
+		return nil, err
+	}
+	body.Signature = sig
+
+	var taintResp api.ReportChangeResponse
+	if err := client.callTaintServer(api.RouteRetractReport, body, &taintResp); err != nil {
+		return nil, err
+	}
+	return taintResp, nil
+}
+
+// ModerateScamReport moves the version version of a report to status, as a moderator of the client's account.
+func (client *Client) ModerateScamReport(reportID string, version int, status string, note string) (interface{}, error) {
+	body := api.ModerateReportRequest{
+		ReportID:    reportID,
+		ModeratorID: client.config.AccountID,
+		Version:     version,
+		Status:      status,
+		Note:        note,
+	}
+
+	sig, err := client.sign(body)
+	if err != nil {
+		return nil, err
+	}
+	body.Signature = sig
+
+	var taintResp api.ReportChangeResponse
+	if err := client.callTaintServer(api.RouteModerateReport, body, &taintResp); err != nil {
+		return nil, err
+	}
+	return taintResp, nil
+}
+
+// sign returns the base64 encoded signature of the JSON of body by the client's key.
+func (client *Client) sign(body interface{}) (string, error) {
+	keyPair, err := getKeyPair(client.config.PrivateKeyFile)
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to get private key")
+	}
+
+	bodyBytes, err := json.Marshal(body)
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to marshal body")
+	}
+
+	sig, err := keyPair.Sign(signaturePolicy, hashPolicy, bodyBytes)
+	if err != nil {
+		return "", errors.Wrap(err, "Unable to sign body")
+	}
+
+	return base64.StdEncoding.EncodeToString(sig), nil
+}
+
 func (client *Client) AllScamReports() (interface{}, error) {
 	var taintResp api.AllScamReportResponse
 	if err := client.callTaintServer(api.RouteAllScamReports, nil, &taintResp); err != nil {
@@ -232,10 +346,22 @@ func (client *Client) callWaveletLedger(tag string, payload string) (interface{}
 }
 
 func (client *Client) callTaintServer(path string, body interface{}, out interface{}) error {
+	data, err := client.requestTaintServer(path, body)
+	if err != nil {
+		return err
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (client *Client) requestTaintServer(path string, body interface{}) ([]byte, error) {
 	prot := "http"
 	u, err := url.Parse(fmt.Sprintf("%s://%s:%d%s", prot, client.config.TaintHost, client.config.TaintPort, path))
 	if err != nil {
-		return err
+		return nil, err
 	}
 	req := &http.Request{
 		Method: "POST",
@@ -245,7 +371,7 @@ func (client *Client) callTaintServer(path string, body interface{}, out interfa
 	if body != nil {
 		rawBody, err := json.Marshal(body)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		req.Body = ioutil.NopCloser(bytes.NewReader(rawBody))
 	}
@@ -253,21 +379,18 @@ func (client *Client) callTaintServer(path string, body interface{}, out interfa
 	c := &http.Client{}
 	resp, err := c.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return errors.Errorf("got an error code %v: %v", resp.Status, string(data))
+		return nil, errors.Errorf("got an error code %v: %v", resp.Status, string(data))
 	}
 
-	if out == nil {
-		return nil
-	}
-	return json.Unmarshal(data, out)
+	return data, nil
 }
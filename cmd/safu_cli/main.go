@@ -3,10 +3,12 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/perlin-network/safu-go/api"
 	"github.com/perlin-network/safu-go/cmd/safu_cli/client"
 	"github.com/perlin-network/safu-go/log"
 	"gopkg.in/urfave/cli.v1"
 	"gopkg.in/urfave/cli.v1/altsrc"
+	"io/ioutil"
 	"os"
 	"sort"
 	"strconv"
@@ -47,6 +49,12 @@ func main() {
 		}),
 	}
 
+	chainFlag := cli.StringFlag{
+		Name:  "chain",
+		Value: "eth",
+		Usage: "Chain the addresses are on: eth, bsc, polygon, goerli, sepolia or btc.",
+	}
+
 	ledgerFlags := []cli.Flag{
 		altsrc.NewStringFlag(cli.StringFlag{
 			Name:  "wavelet.host",
@@ -206,7 +214,7 @@ func main() {
 		{
 			Name:      "query",
 			Usage:     "query an address for the taint value",
-			Flags:     taintFlags,
+			Flags:     append(taintFlags, chainFlag),
 			ArgsUsage: "<address>",
 			Action: func(c *cli.Context) error {
 				client, err := setup(c)
@@ -214,7 +222,7 @@ func main() {
 					return err
 				}
 				address := c.Args().Get(0)
-				res, err := client.Query(address)
+				res, err := client.Query(address, c.String("chain"))
 				if err != nil {
 					return err
 				}
@@ -224,9 +232,114 @@ func main() {
 			},
 		},
 		{
-			Name:      "register_scam_report",
-			Usage:     "make a scam report",
-			Flags:     append(ledgerFlags, taintFlags...),
+			Name:  "path",
+			Usage: "find the shortest paths funds may have taken from one address to another",
+			Flags: append(taintFlags,
+				chainFlag,
+				cli.IntFlag{
+					Name:  "k",
+					Value: 1,
+					Usage: "Number of shortest paths to find.",
+				},
+				cli.IntFlag{
+					Name:  "max_depth",
+					Value: 6,
+					Usage: "Maximum number of hops of a path, and of the crawl (at most 3).",
+				},
+				cli.BoolFlag{
+					Name:  "crawl",
+					Usage: "Crawl forward from the sender before searching.",
+				},
+			),
+			ArgsUsage: "<from_address> <to_address>",
+			Action: func(c *cli.Context) error {
+				client, err := setup(c)
+				if err != nil {
+					return err
+				}
+				from := c.Args().Get(0)
+				to := c.Args().Get(1)
+				res, err := client.Path(from, to, c.String("chain"), c.Int("k"), c.Int("max_depth"), c.Bool("crawl"))
+				if err != nil {
+					return err
+				}
+				jsonOut, _ := json.Marshal(res)
+				fmt.Printf("%s\n", jsonOut)
+				return nil
+			},
+		},
+		{
+			Name:  "export-graph",
+			Usage: "export the neighborhood of an address as a DOT, GraphML, GEXF or Cytoscape.js JSON file",
+			Flags: append(taintFlags,
+				chainFlag,
+				cli.StringFlag{
+					Name:  "format",
+					Value: "graphml",
+					Usage: "Graph file format: dot, graphml, gexf or cytoscape.",
+				},
+				cli.StringFlag{
+					Name:  "direction",
+					Value: "forward",
+					Usage: "Direction transfers are followed in: forward, backward or both.",
+				},
+				cli.IntFlag{
+					Name:  "depth",
+					Value: 2,
+					Usage: "Number of hops from the address.",
+				},
+				cli.StringFlag{
+					Name:  "min_value",
+					Usage: "Drop the transfers of less than a value, in whole units of the asset transferred.",
+				},
+				cli.IntFlag{
+					Name:  "min_taint",
+					Usage: "Drop the addresses tainted less than a score.",
+				},
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "Write the graph to `FILE` instead of the standard output.",
+				},
+			),
+			ArgsUsage: "<address>",
+			Action: func(c *cli.Context) error {
+				client, err := setup(c)
+				if err != nil {
+					return err
+				}
+				req := api.ExportGraphRequest{
+					SubgraphRequest: api.SubgraphRequest{
+						Chain:     c.String("chain"),
+						Address:   c.Args().Get(0),
+						Direction: c.String("direction"),
+						Depth:     c.Int("depth"),
+						MinValue:  c.String("min_value"),
+						MinTaint:  c.Int("min_taint"),
+					},
+					Format: c.String("format"),
+				}
+				res, err := client.ExportGraph(req)
+				if err != nil {
+					return err
+				}
+				if output := c.String("output"); output != "" {
+					return ioutil.WriteFile(output, res, 0644)
+				}
+				_, err = os.Stdout.Write(res)
+				return err
+			},
+		},
+		{
+			Name:  "register_scam_report",
+			Usage: "make a scam report",
+			Flags: append(append(ledgerFlags, taintFlags...),
+				chainFlag,
+				cli.StringFlag{
+					Name:  "direction",
+					Value: "forward",
+					Usage: "Crawl direction used to trace the scammer's funds: forward, backward or both.",
+				},
+			),
 			ArgsUsage: "<scammer_address> <victim_address> <title> <content>",
 			Action: func(c *cli.Context) error {
 				client, err := setup(c)
@@ -237,7 +350,76 @@ func main() {
 				victimAddress := c.Args().Get(1)
 				title := c.Args().Get(2)
 				content := c.Args().Get(3)
-				res, err := client.RegisterScamReport(scammerAddress, victimAddress, title, content)
+				res, err := client.RegisterScamReport(scammerAddress, victimAddress, title, content, c.String("chain"), c.String("direction"))
+				if err != nil {
+					return err
+				}
+				jsonOut, _ := json.Marshal(res)
+				fmt.Printf("%s\n", jsonOut)
+				return nil
+			},
+		},
+		{
+			Name:      "edit_scam_report",
+			Usage:     "edit the title and content of your scam report, as of its latest version",
+			Flags:     append(ledgerFlags, taintFlags...),
+			ArgsUsage: "<report_id> <version> <title> <content>",
+			Action: func(c *cli.Context) error {
+				client, err := setup(c)
+				if err != nil {
+					return err
+				}
+				version, err := strconv.Atoi(c.Args().Get(1))
+				if err != nil {
+					return err
+				}
+				res, err := client.EditScamReport(c.Args().Get(0), version, c.Args().Get(2), c.Args().Get(3))
+				if err != nil {
+					return err
+				}
+				jsonOut, _ := json.Marshal(res)
+				fmt.Printf("%s\n", jsonOut)
+				return nil
+			},
+		},
+		{
+			Name:      "retract_scam_report",
+			Usage:     "retract your scam report, as of its latest version",
+			Flags:     append(ledgerFlags, taintFlags...),
+			ArgsUsage: "<report_id> <version> [reason]",
+			Action: func(c *cli.Context) error {
+				client, err := setup(c)
+				if err != nil {
+					return err
+				}
+				version, err := strconv.Atoi(c.Args().Get(1))
+				if err != nil {
+					return err
+				}
+				res, err := client.RetractScamReport(c.Args().Get(0), version, c.Args().Get(2))
+				if err != nil {
+					return err
+				}
+				jsonOut, _ := json.Marshal(res)
+				fmt.Printf("%s\n", jsonOut)
+				return nil
+			},
+		},
+		{
+			Name:      "moderate_scam_report",
+			Usage:     "move a scam report to under_review, verified or rejected, as a moderator",
+			Flags:     append(ledgerFlags, taintFlags...),
+			ArgsUsage: "<report_id> <version> <status> [note]",
+			Action: func(c *cli.Context) error {
+				client, err := setup(c)
+				if err != nil {
+					return err
+				}
+				version, err := strconv.Atoi(c.Args().Get(1))
+				if err != nil {
+					return err
+				}
+				res, err := client.ModerateScamReport(c.Args().Get(0), version, c.Args().Get(2), c.Args().Get(3))
 				if err != nil {
 					return err
 				}
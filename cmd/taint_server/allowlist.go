@@ -0,0 +1,223 @@
+package main
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/log"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var chainFlag = cli.StringFlag{
+	Name:  "chain",
+	Value: string(model.ChainEthereum),
+	Usage: "`CHAIN` of the address.",
+}
+
+var actorFlag = cli.StringFlag{
+	Name:  "actor",
+	Usage: "`ACTOR` recorded in the audit trail. Defaults to $USER.",
+}
+
+var allowlistCommand = cli.Command{
+	Name:  "allowlist",
+	Usage: "Manage the addresses known to be good, whose taint is capped and which don't pass taint on",
+	Subcommands: []cli.Command{
+		{
+			Name:      "add",
+			Usage:     "Allowlist an address",
+			ArgsUsage: "<address>",
+			Flags: []cli.Flag{
+				chainFlag,
+				actorFlag,
+				cli.StringFlag{
+					Name:  "reason",
+					Usage: "`REASON` the address is known to be good, e.g. \"Binance hot wallet\".",
+				},
+				cli.StringFlag{
+					Name:  "expires",
+					Usage: "When the entry expires, as a duration from now such as 720h or an RFC 3339 `TIME`. Entries don't expire by default.",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return errors.New("expected a single address")
+				}
+
+				chain, err := model.ParseChain(c.String("chain"))
+				if err != nil {
+					return err
+				}
+
+				expiresAt, err := parseExpiry(c.String("expires"), time.Now())
+				if err != nil {
+					return err
+				}
+
+				store := database.NewTieDotStore(c.GlobalString("db.path"))
+				defer store.Close()
+
+				e := &database.AllowlistEntry{
+					Chain:     chain,
+					Address:   c.Args().First(),
+					Reason:    c.String("reason"),
+					Actor:     actor(c),
+					ExpiresAt: expiresAt,
+				}
+				if err := store.PutAllowlistEntry(e); err != nil {
+					return err
+				}
+
+				log.Info().Str("chain", string(chain)).Str("address", model.DisplayAddress(chain, e.Address)).Msg("Allowlisted address.")
+				return nil
+			},
+		},
+		{
+			Name:      "remove",
+			Usage:     "Remove an address from the allowlist",
+			ArgsUsage: "<address>",
+			Flags: []cli.Flag{
+				chainFlag,
+				actorFlag,
+				cli.StringFlag{
+					Name:  "reason",
+					Usage: "`REASON` the address is removed.",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				if c.NArg() != 1 {
+					return errors.New("expected a single address")
+				}
+
+				chain, err := model.ParseChain(c.String("chain"))
+				if err != nil {
+					return err
+				}
+
+				store := database.NewTieDotStore(c.GlobalString("db.path"))
+				defer store.Close()
+
+				return store.DeleteAllowlistEntry(chain, c.Args().First(), actor(c), c.String("reason"))
+			},
+		},
+		{
+			Name:  "list",
+			Usage: "List the allowlisted addresses",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "chain",
+					Usage: "`CHAIN` whose entries are listed. Entries of every chain are listed by default.",
+				},
+				cli.BoolFlag{
+					Name:  "expired",
+					Usage: "List expired entries too.",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				store := database.NewTieDotStore(c.GlobalString("db.path"))
+				defer store.Close()
+
+				now := time.Now().Unix()
+				return store.ForEachAllowlistEntry(model.Chain(c.String("chain")), func(e *database.AllowlistEntry) error {
+					if e.Expired(now) && !c.Bool("expired") {
+						return nil
+					}
+
+					log.Info().
+						Str("chain", string(e.Chain)).
+						Str("address", model.DisplayAddress(e.Chain, e.Address)).
+						Str("reason", e.Reason).
+						Str("actor", e.Actor).
+						Int64("expires_at", e.ExpiresAt).
+						Bool("expired", e.Expired(now)).
+						Msg("Allowlisted.")
+					return nil
+				})
+			},
+		},
+	},
+}
+
+var auditLogCommand = cli.Command{
+	Name:  "audit_log",
+	Usage: "Print the audit trail of allowlist changes and taint suppressions",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "since",
+			Usage: "Print the events since `TIME`, as a duration before now such as 24h or an RFC 3339 time. Every event is printed by default.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		var since int64
+		if s := c.String("since"); s != "" {
+			d, err := time.ParseDuration(s)
+			if err == nil {
+				since = time.Now().Add(-d).Unix()
+			} else if t, err := time.Parse(time.RFC3339, s); err == nil {
+				since = t.Unix()
+			} else {
+				return errors.Errorf("expected a duration or an RFC 3339 time, got %q", s)
+			}
+		}
+
+		store := database.NewTieDotStore(c.GlobalString("db.path"))
+		defer store.Close()
+
+		return store.ForEachAuditEvent(since, func(e *database.AuditEvent) error {
+			event := log.Info().
+				Str("action", string(e.Action)).
+				Str("chain", string(e.Chain)).
+				Str("address", model.DisplayAddress(e.Chain, e.Address)).
+				Str("at", time.Unix(e.Time, 0).UTC().Format(time.RFC3339))
+
+			if e.Actor != "" {
+				event = event.Str("actor", e.Actor).Str("reason", e.Reason)
+			}
+			if e.Source != "" {
+				event = event.Str("source", model.DisplayAddress(e.Chain, e.Source)).Int("taint", e.Taint).Int("cap", e.Cap)
+			}
+
+			event.Msg("Audit event.")
+			return nil
+		})
+	},
+}
+
+// parseExpiry parses an expiry given as a duration from now or an RFC 3339 time into a unix timestamp, 0 if s is
+// empty. Bare numbers are taken as unix timestamps.
+func parseExpiry(s string, now time.Time) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(d).Unix(), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Unix(), nil
+	}
+
+	if ts, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return ts, nil
+	}
+
+	return 0, errors.Errorf("expected a duration, an RFC 3339 time or a unix timestamp, got %q", s)
+}
+
+// actor returns the actor recorded in the audit trail for changes made by a command.
+func actor(c *cli.Context) string {
+	if a := c.String("actor"); a != "" {
+		return a
+	}
+
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+
+	return "cli"
+}
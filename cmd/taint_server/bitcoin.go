@@ -0,0 +1,108 @@
+package main
+
+import (
+	"github.com/perlin-network/safu-go/bitcoin"
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/log"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var ingestBitcoinCommand = cli.Command{
+	Name:  "ingest_btc",
+	Usage: "Ingest Bitcoin blocks into the address graph, from a Bitcoin Core node or from block fixtures",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "btc.rpc",
+			Value: "http://localhost:8332",
+			Usage: "Bitcoin Core JSON-RPC `URL`.",
+		},
+		cli.StringFlag{
+			Name:  "btc.user",
+			Usage: "Bitcoin Core JSON-RPC user.",
+		},
+		cli.StringFlag{
+			Name:  "btc.password",
+			Usage: "Bitcoin Core JSON-RPC password.",
+		},
+		cli.Uint64Flag{
+			Name:  "from",
+			Usage: "First block `HEIGHT` to ingest from the node.",
+		},
+		cli.Uint64Flag{
+			Name:  "to",
+			Usage: "Last block `HEIGHT` to ingest from the node.",
+		},
+		cli.StringFlag{
+			Name:  "fixtures",
+			Usage: "Ingest the getblock outputs stored as JSON files in `DIR` instead of querying a node.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		var blocks []*bitcoin.Block
+
+		if dir := c.String("fixtures"); dir != "" {
+			var err error
+			if blocks, err = bitcoin.LoadBlockFixtures(dir); err != nil {
+				return err
+			}
+		} else {
+			rpc := bitcoin.NewRPC(c.String("btc.rpc"), c.String("btc.user"), c.String("btc.password"))
+
+			if c.Uint64("to") < c.Uint64("from") {
+				return errors.New("the last block height must not be lower than the first one")
+			}
+
+			for height := c.Uint64("from"); height <= c.Uint64("to"); height++ {
+				block, err := rpc.BlockAt(height)
+				if err != nil {
+					return errors.Wrapf(err, "unable to fetch block %d", height)
+				}
+				blocks = append(blocks, block)
+			}
+		}
+
+		store := database.NewTieDotStore(c.GlobalString("db.path"))
+		defer store.Close()
+
+		return ingestBitcoin(store, blocks)
+	},
+}
+
+// ingestBitcoin stores the address graph of blocks and the clusters found by the common-input-ownership heuristic.
+func ingestBitcoin(store *database.TieDotStore, blocks []*bitcoin.Block) error {
+	ingester := bitcoin.NewIngester()
+
+	graph := model.NewGraph(model.ChainBitcoin)
+
+	for _, block := range blocks {
+		edges, err := ingester.AddBlock(block)
+		if err != nil {
+			return errors.Wrapf(err, "unable to ingest block %s", block.Hash)
+		}
+
+		for _, e := range edges {
+			graph.AddEdge(e)
+		}
+	}
+
+	if err := store.InsertGraph(graph); err != nil {
+		return err
+	}
+
+	clusters := ingester.Clusters()
+	if err := store.InsertClusters(model.ChainBitcoin, clusters); err != nil {
+		return err
+	}
+
+	log.Info().
+		Int("blocks", len(blocks)).
+		Int("addresses", graph.Len()).
+		Int("edges", graph.Size()).
+		Int("tx_links", len(ingester.TxLinks())).
+		Int("clusters", len(clusters)).
+		Msg("Ingested Bitcoin blocks.")
+
+	return nil
+}
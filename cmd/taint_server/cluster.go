@@ -0,0 +1,59 @@
+package main
+
+import (
+	"github.com/perlin-network/safu-go/cluster"
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/log"
+	"github.com/perlin-network/safu-go/model"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var clusterCommand = cli.Command{
+	Name:  "cluster",
+	Usage: "Cluster the stored addresses of a chain into entities, by deposit address reuse and shared funding",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "chain",
+			Value: string(model.ChainEthereum),
+			Usage: "`CHAIN` whose stored graph is clustered.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		chain, err := model.ParseChain(c.String("chain"))
+		if err != nil {
+			return err
+		}
+
+		store := database.NewTieDotStore(c.GlobalString("db.path"))
+		defer store.Close()
+
+		return clusterChain(store, chain)
+	},
+}
+
+// clusterChain applies the clustering heuristics to the whole stored graph of chain, and merges the clusters found
+// into the stored ones.
+func clusterChain(store *database.TieDotStore, chain model.Chain) error {
+	graph, err := store.LoadChainGraph(chain)
+	if err != nil {
+		return err
+	}
+
+	c := cluster.New()
+	cluster.DepositReuse(c, graph)
+	cluster.SharedFunding(c, graph)
+
+	clusters := c.Clusters()
+	if err := store.InsertClusters(chain, clusters); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("chain", string(chain)).
+		Int("addresses", graph.Len()).
+		Int("edges", graph.Size()).
+		Int("clusters", len(clusters)).
+		Msg("Clustered addresses.")
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/label"
+	"github.com/perlin-network/safu-go/log"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var importLabelsCommand = cli.Command{
+	Name:      "import_labels",
+	Usage:     "Import address labels from CSV or JSON files into the label registry",
+	ArgsUsage: "<file>...",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "source",
+			Usage: "`SOURCE` the labels without a source are attributed to. Defaults to the file name.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() == 0 {
+			return errors.New("no label file given")
+		}
+
+		store := database.NewTieDotStore(c.GlobalString("db.path"))
+		defer store.Close()
+
+		for _, path := range c.Args() {
+			source := c.String("source")
+			if source == "" {
+				source = filepath.Base(path)
+			}
+
+			labels, err := loadLabels(path, source)
+			if err != nil {
+				return errors.Wrapf(err, "unable to load %s", path)
+			}
+
+			if err := store.PutLabels(labels...); err != nil {
+				return err
+			}
+
+			log.Info().Str("file", path).Int("labels", len(labels)).Msg("Imported labels.")
+		}
+
+		return nil
+	},
+}
+
+// loadLabels reads the labels of a .csv or .json file.
+func loadLabels(path string, source string) ([]*label.Label, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return label.ParseCSV(f, source)
+	}
+
+	return label.ParseJSON(f, source)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/label"
+	"github.com/perlin-network/safu-go/log"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var importListCommand = cli.Command{
+	Name:      "import_list",
+	Usage:     "Import a new version of a sanctions list or blocklist into the label registry, and print what it changed",
+	ArgsUsage: "<file>",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "source",
+			Usage: "`SOURCE` the list is published by, e.g. ofac. Its previous labels are replaced. Defaults to the file name.",
+		},
+		cli.StringFlag{
+			Name:  "format",
+			Usage: "`FORMAT` of the list: sdn_xml, sdn_csv or plain. Defaults to sdn_xml for .xml files, sdn_csv for .csv files, and plain otherwise.",
+		},
+		cli.StringFlag{
+			Name:  "category",
+			Value: string(label.CategorySanctioned),
+			Usage: "`CATEGORY` of the listed addresses.",
+		},
+		cli.StringFlag{
+			Name:  "chain",
+			Value: string(model.ChainEthereum),
+			Usage: "`CHAIN` of the addresses of plain lists that don't name one.",
+		},
+		cli.StringFlag{
+			Name:  "name",
+			Usage: "`NAME` of the labels of plain lists.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		if c.NArg() != 1 {
+			return errors.New("expected a single list file")
+		}
+		path := c.Args().First()
+
+		opts := label.ListOptions{
+			Source: c.String("source"),
+			Name:   c.String("name"),
+		}
+		if opts.Source == "" {
+			opts.Source = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+
+		var err error
+		if opts.Category, err = label.ParseCategory(c.String("category")); err != nil {
+			return err
+		}
+		if opts.Chain, err = model.ParseChain(c.String("chain")); err != nil {
+			return err
+		}
+
+		format, err := listFormat(path, c.String("format"))
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		list, err := label.ParseList(f, format, opts)
+		if err != nil {
+			return errors.Wrapf(err, "unable to load %s", path)
+		}
+
+		store := database.NewTieDotStore(c.GlobalString("db.path"))
+		defer store.Close()
+
+		version, err := store.ImportList(opts.Source, list.Labels)
+		if err != nil {
+			return err
+		}
+
+		for _, address := range list.Skipped {
+			log.Warn().Str("address", address).Msg("Skipped unsupported address.")
+		}
+
+		for _, e := range version.Added {
+			log.Info().Str("chain", string(e.Chain)).Str("address", e.Address).Msg("Added.")
+		}
+
+		for _, e := range version.Removed {
+			log.Info().Str("chain", string(e.Chain)).Str("address", e.Address).Msg("Removed.")
+		}
+
+		log.Info().
+			Str("source", version.Source).
+			Int("version", version.Version).
+			Int("entries", version.Entries).
+			Int("added", len(version.Added)).
+			Int("removed", len(version.Removed)).
+			Int("skipped", len(list.Skipped)).
+			Msg("Imported list.")
+
+		return nil
+	},
+}
+
+// listFormat returns the format of the list at path: format if given, or the format its extension suggests.
+func listFormat(path string, format string) (label.ListFormat, error) {
+	if format != "" {
+		return label.ParseListFormat(format)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml":
+		return label.ListSDNXML, nil
+	case ".csv":
+		return label.ListSDNCSV, nil
+	}
+
+	return label.ListPlain, nil
+}
@@ -4,14 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/perlin-network/safu-go/api"
+	"github.com/perlin-network/safu-go/crawler"
 	"github.com/perlin-network/safu-go/database"
 	"github.com/perlin-network/safu-go/etherscan"
 	"github.com/perlin-network/safu-go/ledger"
 	"github.com/perlin-network/safu-go/log"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/perlin-network/safu-go/policy"
+	"github.com/pkg/errors"
 	"gopkg.in/urfave/cli.v1"
 	"gopkg.in/urfave/cli.v1/altsrc"
 	"os"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -26,6 +31,12 @@ type Config struct {
 	WaveletHost     string
 	WaveletPort     uint
 	SmartContractID string
+	ExplorerKeys    map[model.Chain]string
+	RPCURLs         map[model.Chain]string
+	RPCTrace        bool
+	AdminToken      string
+	PolicyFile      string
+	Moderators      []string
 }
 
 func main() {
@@ -78,6 +89,31 @@ func main() {
 			Value: 3000,
 			Usage: "Wavelet chain api port `PORT`.",
 		}),
+		altsrc.NewStringSliceFlag(cli.StringSliceFlag{
+			Name:  "explorer.keys",
+			Usage: "Etherscan compatible explorer API keys as `CHAIN=KEY` pairs. Chains without a key or RPC endpoint aren't crawled.",
+		}),
+		altsrc.NewStringSliceFlag(cli.StringSliceFlag{
+			Name:  "rpc.urls",
+			Usage: "JSON-RPC endpoints to read ERC-20 Transfer logs from, in addition to the explorers, as `CHAIN=URL` pairs.",
+		}),
+		altsrc.NewBoolFlag(cli.BoolFlag{
+			Name:  "rpc.trace",
+			Usage: "Trace crawled transactions with debug_traceTransaction on the rpc.urls nodes to find internal transfers, on chains without an explorer API key.",
+		}),
+		altsrc.NewStringFlag(cli.StringFlag{
+			Name:   "admin.token",
+			Usage:  "Bearer `TOKEN` required by the admin routes. Admin routes are disabled if empty.",
+			EnvVar: "SAFU_ADMIN_TOKEN",
+		}),
+		altsrc.NewStringFlag(cli.StringFlag{
+			Name:  "policy.file",
+			Usage: "TOML or YAML `FILE` of the scoring policy, reloaded when it changes. The default policy is used if empty.",
+		}),
+		altsrc.NewStringSliceFlag(cli.StringSliceFlag{
+			Name:  "moderators",
+			Usage: "Hex encoded public keys of the Wavelet accounts allowed to moderate reports, as `PUBLIC_KEY`s.",
+		}),
 		altsrc.NewStringFlag(cli.StringFlag{
 			Name:  "contract.id",
 			Value: "C-123",
@@ -91,6 +127,20 @@ func main() {
 	}
 
 	app.Action = func(c *cli.Context) error {
+		explorerKeys, err := chainPairs(c.StringSlice("explorer.keys"))
+		if err != nil {
+			return err
+		}
+
+		rpcURLs, err := chainPairs(c.StringSlice("rpc.urls"))
+		if err != nil {
+			return err
+		}
+
+		if _, ok := explorerKeys[model.ChainEthereum]; !ok {
+			explorerKeys[model.ChainEthereum] = "4EIR7V4K5QBWDUGJKHFK4BGZ6HWD1NIFT1"
+		}
+
 		config := &Config{
 			PrivateKeyFile:  c.String("private_key_file"),
 			TaintHost:       c.String("taint.host"),
@@ -101,6 +151,12 @@ func main() {
 			WaveletHost:     c.String("wavelet.host"),
 			WaveletPort:     c.Uint("wavelet.port"),
 			SmartContractID: c.String("contract.id"),
+			ExplorerKeys:    explorerKeys,
+			RPCURLs:         rpcURLs,
+			RPCTrace:        c.Bool("rpc.trace"),
+			AdminToken:      c.String("admin.token"),
+			PolicyFile:      c.String("policy.file"),
+			Moderators:      c.StringSlice("moderators"),
 		}
 
 		// start the plugin
@@ -111,6 +167,18 @@ func main() {
 		return nil
 	}
 
+	app.Commands = []cli.Command{
+		ingestBitcoinCommand,
+		clusterCommand,
+		rankCommand,
+		importLabelsCommand,
+		importListCommand,
+		allowlistCommand,
+		auditLogCommand,
+		recomputeCommand,
+		verifyTaintCommand,
+	}
+
 	sort.Sort(cli.FlagsByName(app.Flags))
 	sort.Sort(cli.CommandsByName(app.Commands))
 
@@ -121,7 +189,12 @@ func main() {
 }
 
 func runServer(c *Config) error {
-	jsonConfig, _ := json.MarshalIndent(c, "", "  ")
+	redacted := *c
+	if redacted.AdminToken != "" {
+		redacted.AdminToken = "redacted"
+	}
+
+	jsonConfig, _ := json.MarshalIndent(&redacted, "", "  ")
 	log.Debug().Msgf("Config: %s", string(jsonConfig))
 
 	// setup database
@@ -132,7 +205,20 @@ func runServer(c *Config) error {
 	// TODO: setup main loop to watch the ledger
 
 	var store = database.NewTieDotStore(c.DatabasePath)
-	var esClient = etherscan.NewESClient("4EIR7V4K5QBWDUGJKHFK4BGZ6HWD1NIFT1")
+
+	sources, err := setupSources(c)
+	if err != nil {
+		return err
+	}
+
+	policies, err := policy.NewEngine(c.PolicyFile)
+	if err != nil {
+		return err
+	}
+	go policies.Watch(policy.DefaultReloadInterval, nil)
+
+	log.Info().Str("version", policies.Policy().Version).Msg("Loaded the scoring policy.")
+
 	ledger := &ledger.Ledger{
 		PrivateKeyFile:  c.PrivateKeyFile,
 		WCTLPath:        c.WCTLPath,
@@ -142,7 +228,62 @@ func runServer(c *Config) error {
 	}
 
 	// listen for api calls
-	api.Run(fmt.Sprintf("%s:%d", c.TaintHost, c.TaintPort), esClient, store, ledger)
+	api.Run(fmt.Sprintf("%s:%d", c.TaintHost, c.TaintPort), sources, store, ledger, c.AdminToken, policies, c.Moderators)
 
 	return nil
 }
+
+// setupSources registers a crawl source for every chain with an explorer API key or a JSON-RPC endpoint.
+func setupSources(c *Config) (*crawler.Registry, error) {
+	registry := crawler.NewRegistry()
+
+	for _, chain := range model.Chains() {
+		var sources []crawler.Source
+
+		if key, ok := c.ExplorerKeys[chain]; ok {
+			esClient, err := etherscan.NewESClient(chain, key)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, esClient)
+		}
+
+		if url, ok := c.RPCURLs[chain]; ok {
+			rpc := crawler.NewRPC(chain, url)
+
+			// explorers report internal transfers themselves
+			if c.RPCTrace && len(sources) == 0 {
+				sources = append(sources, crawler.Traced(rpc, rpc))
+			} else {
+				sources = append(sources, rpc)
+			}
+		}
+
+		if len(sources) > 0 {
+			registry.Register(chain, crawler.Multi(sources...))
+		}
+	}
+
+	return registry, nil
+}
+
+// chainPairs parses CHAIN=VALUE pairs.
+func chainPairs(pairs []string) (map[model.Chain]string, error) {
+	m := make(map[model.Chain]string)
+
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("expected CHAIN=VALUE, got %q", pair)
+		}
+
+		chain, err := model.ParseChain(parts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		m[chain] = parts[1]
+	}
+
+	return m, nil
+}
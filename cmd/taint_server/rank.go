@@ -0,0 +1,87 @@
+package main
+
+import (
+	"time"
+
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/log"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/perlin-network/safu-go/rank"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var rankCommand = cli.Command{
+	Name:  "rank",
+	Usage: "Compute the graph risk of the stored addresses of a chain, by PageRank personalized on reported scammers",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "chain",
+			Value: string(model.ChainEthereum),
+			Usage: "`CHAIN` whose stored graph is ranked.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		chain, err := model.ParseChain(c.String("chain"))
+		if err != nil {
+			return err
+		}
+
+		store := database.NewTieDotStore(c.GlobalString("db.path"))
+		defer store.Close()
+
+		return rankChain(store, chain)
+	},
+}
+
+// rankChain computes the graph risk of every address of the stored graph of chain, seeding the random walk with
+// the reported scammer addresses weighted by their number of reports, and replaces the stored graph risks.
+func rankChain(store *database.TieDotStore, chain model.Chain) error {
+	graph, err := store.LoadChainGraph(chain)
+	if err != nil {
+		return err
+	}
+
+	seeds := make(map[string]float64)
+	err = store.ForEachReport(func(report *database.Report) error {
+		if report.Chain == chain {
+			seeds[model.NormalizeAddress(chain, report.ScammerAddress)]++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ranks := rank.PersonalizedPageRank(graph, seeds)
+	scores := rank.Normalize(ranks)
+
+	now := time.Now().Unix()
+
+	var risks []*database.GraphRisk
+	for _, address := range graph.Addresses() {
+		if ranks[address] == 0 {
+			continue
+		}
+
+		risks = append(risks, &database.GraphRisk{
+			Chain:      chain,
+			Address:    address,
+			Rank:       ranks[address],
+			Score:      scores[address],
+			ComputedAt: now,
+		})
+	}
+
+	if err := store.ReplaceGraphRisks(chain, risks); err != nil {
+		return err
+	}
+
+	log.Info().
+		Str("chain", string(chain)).
+		Int("addresses", graph.Len()).
+		Int("seeds", len(seeds)).
+		Int("ranked", len(risks)).
+		Msg("Ranked addresses.")
+
+	return nil
+}
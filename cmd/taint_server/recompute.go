@@ -0,0 +1,55 @@
+package main
+
+import (
+	"time"
+
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/log"
+	"github.com/perlin-network/safu-go/policy"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var recomputeCommand = cli.Command{
+	Name:  "recompute",
+	Usage: "Wipe and recompute all taint from the stored reports and graphs, resuming an interrupted recomputation",
+	Flags: []cli.Flag{
+		cli.BoolFlag{
+			Name:  "restart",
+			Usage: "Discard an interrupted recomputation instead of resuming it.",
+		},
+	},
+	Action: func(c *cli.Context) error {
+		policies, err := policy.NewEngine(c.GlobalString("policy.file"))
+		if err != nil {
+			return err
+		}
+		p := policies.Policy()
+
+		store := database.NewTieDotStore(c.GlobalString("db.path"))
+		defer store.Close()
+
+		// log progress at most every few seconds
+		var logged time.Time
+
+		state, err := store.RecomputeTaint(p, c.Bool("restart"), func(state *database.RecomputeState) {
+			if time.Since(logged) < 5*time.Second && state.Done < state.Total {
+				return
+			}
+			logged = time.Now()
+
+			log.Info().Int("done", state.Done).Int("total", state.Total).Msg("Recomputing taint.")
+		})
+		if err != nil {
+			return err
+		}
+
+		log.Info().
+			Str("id", state.ID).
+			Str("policy", p.Version).
+			Int("sources", state.Total).
+			Dur("took", time.Duration(state.FinishedAt-state.StartedAt)*time.Second).
+			Msg("Recomputed taint.")
+
+		return nil
+	},
+}
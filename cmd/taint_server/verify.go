@@ -0,0 +1,51 @@
+package main
+
+import (
+	"github.com/perlin-network/safu-go/database"
+	"github.com/perlin-network/safu-go/log"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/perlin-network/safu-go/policy"
+	"github.com/pkg/errors"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var verifyTaintCommand = cli.Command{
+	Name:  "verify_taint",
+	Usage: "Check that the stored taint, updated incrementally, equals a full recomputation",
+	Action: func(c *cli.Context) error {
+		policies, err := policy.NewEngine(c.GlobalString("policy.file"))
+		if err != nil {
+			return err
+		}
+		p := policies.Policy()
+
+		store := database.NewTieDotStore(c.GlobalString("db.path"))
+		defer store.Close()
+
+		mismatches, err := store.VerifyTaint(p)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range mismatches {
+			log.Warn().
+				Str("chain", string(m.Chain)).
+				Str("address", model.DisplayAddress(m.Chain, m.Address)).
+				Str("asset", m.Asset).
+				Str("report", m.ReportID).
+				Int("stored", m.Stored).
+				Int("recomputed", m.Recomputed).
+				Int64("stored_since", m.StoredSince).
+				Int64("recomputed_since", m.RecomputedSince).
+				Msg("Stored taint differs from a full recomputation.")
+		}
+
+		if len(mismatches) > 0 {
+			return errors.Errorf("stored taint differs from a full recomputation in %d places; run recompute to fix it", len(mismatches))
+		}
+
+		log.Info().Str("policy", p.Version).Msg("Stored taint equals a full recomputation.")
+
+		return nil
+	},
+}
@@ -0,0 +1,140 @@
+package crawler
+
+import (
+	"log"
+	"time"
+
+	"github.com/perlin-network/safu-go/model"
+)
+
+const (
+	// MaxRequests bounds the number of accounts a single crawl fetches transfers for.
+	MaxRequests = 30
+	// MaxAccounts bounds the number of accounts a single crawl visits.
+	MaxAccounts = 256
+	// RequestDelay is the pause between two fetches, to stay under the providers' rate limits.
+	RequestDelay = 250 * time.Millisecond
+)
+
+// Source fetches the transfers that touch an address, in either direction.
+type Source interface {
+	Transfers(address string) ([]*model.Edge, error)
+}
+
+type multi []Source
+
+// Multi combines several sources into one, returning the transfers of all of them. Transfers reported without
+// their position by a source are dropped if another source reports them with it.
+func Multi(sources ...Source) Source {
+	return multi(sources)
+}
+
+func (m multi) Transfers(address string) ([]*model.Edge, error) {
+	var edges []*model.Edge
+
+	for _, src := range m {
+		e, err := src.Transfers(address)
+		if err != nil {
+			return nil, err
+		}
+
+		edges = append(edges, e...)
+	}
+
+	positioned := make(map[string]bool)
+	for _, e := range edges {
+		if e.Position != "" {
+			positioned[e.TransferKey()] = true
+		}
+	}
+
+	kept := edges[:0]
+	for _, e := range edges {
+		if e.Position == "" && positioned[e.TransferKey()] {
+			continue
+		}
+		kept = append(kept, e)
+	}
+
+	return kept, nil
+}
+
+// Crawl walks the transaction graph of chain around address breadth-first, following transfers in the given
+// direction, and returns the graph of the transfers followed, each with its Direction set to the direction it was
+// followed in.
+func Crawl(src Source, chain model.Chain, address string, direction model.Direction) (*model.Graph, error) {
+	return CrawlDepth(src, chain, address, direction, -1)
+}
+
+// CrawlDepth is Crawl, but only follows transfers of the accounts within maxDepth hops of address. A negative
+// maxDepth doesn't bound the crawl.
+func CrawlDepth(src Source, chain model.Chain, address string, direction model.Direction, maxDepth int) (*model.Graph, error) {
+	address = model.NormalizeAddress(chain, address)
+
+	graph := model.NewGraph(chain)
+	graph.AddVertex(address)
+
+	ids := []string{address}
+	searched := make(map[string]struct{})
+	depths := map[string]int{address: 0}
+
+	for count := 0; len(ids) > 0 && count < MaxRequests; count++ {
+		time.Sleep(RequestDelay)
+
+		accountID := ids[0]
+		ids = ids[1:len(ids):len(ids)]
+
+		if len(searched) >= MaxAccounts {
+			return graph, nil
+		}
+		if _, ok := searched[accountID]; ok {
+			continue
+		}
+		if maxDepth >= 0 && depths[accountID] >= maxDepth {
+			continue
+		}
+		searched[accountID] = struct{}{}
+
+		edges, err := src.Transfers(accountID)
+		if err != nil {
+			log.Printf("unable to retrieve transfers of %s: %+v\n", accountID, err)
+			continue
+		}
+
+		for _, e := range edges {
+			e.From = model.NormalizeAddress(chain, e.From)
+			e.To = model.NormalizeAddress(chain, e.To)
+
+			// contract creations have no recipient
+			if e.To == "" {
+				continue
+			}
+
+			// the same transfer is seen from both of its ends, and may be reported by several sources
+			if graph.HasEdge(e) {
+				continue
+			}
+
+			if direction.Forward() && e.From == accountID {
+				e.Direction = model.DirectionForward
+				graph.AddEdge(e)
+				ids = append(ids, e.To)
+				reach(depths, e.To, depths[accountID]+1)
+			} else if direction.Backward() && e.To == accountID {
+				e.Direction = model.DirectionBackward
+				graph.AddEdge(e)
+				ids = append(ids, e.From)
+				reach(depths, e.From, depths[accountID]+1)
+			}
+		}
+	}
+
+	return graph, nil
+}
+
+// reach records that address is depth hops away from the crawl's origin, unless it was reached sooner.
+func reach(depths map[string]int, address string, depth int) {
+	if _, ok := depths[address]; !ok {
+		depths[address] = depth
+	}
+}
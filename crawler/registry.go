@@ -0,0 +1,57 @@
+package crawler
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+)
+
+// Registry holds the source to crawl each chain with.
+type Registry struct {
+	mu      sync.RWMutex
+	sources map[model.Chain]Source
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		sources: make(map[model.Chain]Source),
+	}
+}
+
+// Register sets the source used to crawl chain, replacing any previous one.
+func (r *Registry) Register(chain model.Chain, src Source) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sources[chain] = src
+}
+
+// Source returns the source registered for chain.
+func (r *Registry) Source(chain model.Chain) (Source, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	src, ok := r.sources[chain]
+	if !ok {
+		return nil, errors.Errorf("no provider registered for chain %s", chain)
+	}
+
+	return src, nil
+}
+
+// Chains returns the chains a source is registered for, in alphabetical order.
+func (r *Registry) Chains() []model.Chain {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var chains []model.Chain
+	for c := range r.sources {
+		chains = append(chains, c)
+	}
+
+	sort.Slice(chains, func(i, j int) bool { return chains[i] < chains[j] })
+
+	return chains
+}
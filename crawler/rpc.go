@@ -0,0 +1,285 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+)
+
+const (
+	// transferTopic is keccak256("Transfer(address,address,uint256)").
+	transferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+	// selectors of the ERC-20 metadata getters
+	symbolSelector   = "0x95d89b41"
+	decimalsSelector = "0x313ce567"
+)
+
+// RPC is a Source reading ERC-20 Transfer event logs from an Ethereum JSON-RPC node.
+type RPC struct {
+	chain  model.Chain
+	url    string
+	client *http.Client
+
+	// FromBlock is the first block logs are searched from.
+	FromBlock uint64
+
+	mu         sync.Mutex
+	nextID     int
+	tokens     map[string]*model.Token
+	blockTimes map[uint64]int64
+}
+
+// NewRPC creates a source reading from the JSON-RPC node of chain at url.
+func NewRPC(chain model.Chain, url string) *RPC {
+	return &RPC{
+		chain:      chain,
+		url:        url,
+		client:     &http.Client{Timeout: 30 * time.Second},
+		tokens:     make(map[string]*model.Token),
+		blockTimes: make(map[uint64]int64),
+	}
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+type rpcLog struct {
+	Address         string   `json:"address"`
+	Topics          []string `json:"topics"`
+	Data            string   `json:"data"`
+	BlockNumber     string   `json:"blockNumber"`
+	TransactionHash string   `json:"transactionHash"`
+	LogIndex        string   `json:"logIndex"`
+}
+
+// call invokes a JSON-RPC method on the node and decodes its result into out.
+func (r *RPC) call(method string, params []interface{}, out interface{}) error {
+	r.mu.Lock()
+	r.nextID++
+	id := r.nextID
+	r.mu.Unlock()
+
+	body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.client.Post(r.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrapf(err, "%s failed", method)
+	}
+	defer resp.Body.Close()
+
+	var res rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return errors.Wrapf(err, "%s returned a malformed response", method)
+	}
+
+	if res.Error != nil {
+		return errors.Errorf("%s failed: %s (code %d)", method, res.Error.Message, res.Error.Code)
+	}
+
+	return json.Unmarshal(res.Result, out)
+}
+
+// Transfers fetches the ERC-20 transfers sent or received by address from the node's Transfer event logs.
+func (r *RPC) Transfers(address string) ([]*model.Edge, error) {
+	topic := addressTopic(address)
+
+	sent, err := r.transferLogs([]interface{}{transferTopic, topic})
+	if err != nil {
+		return nil, err
+	}
+
+	received, err := r.transferLogs([]interface{}{transferTopic, nil, topic})
+	if err != nil {
+		return nil, err
+	}
+
+	var edges []*model.Edge
+
+	for _, l := range append(sent, received...) {
+		// ERC-721 transfers share the event signature but index the token ID as well
+		if len(l.Topics) != 3 {
+			continue
+		}
+
+		token, err := r.token(l.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		blockNumber, err := strconv.ParseUint(strings.TrimPrefix(l.BlockNumber, "0x"), 16, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid block number %q", l.BlockNumber)
+		}
+
+		timestamp, err := r.blockTime(blockNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		logIndex, err := strconv.ParseUint(strings.TrimPrefix(l.LogIndex, "0x"), 16, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid log index %q", l.LogIndex)
+		}
+
+		edge := model.NewEdge(r.chain, topicAddress(l.Topics[1]), topicAddress(l.Topics[2]), l.TransactionHash, "")
+		edge.Position = strconv.FormatUint(logIndex, 10)
+		edge.BlockNumber = blockNumber
+		edge.Timestamp = timestamp
+		edge.Amount = hexToBig(l.Data).String()
+		edge.Token = token
+
+		edges = append(edges, edge)
+	}
+
+	return edges, nil
+}
+
+func (r *RPC) transferLogs(topics []interface{}) ([]rpcLog, error) {
+	filter := map[string]interface{}{
+		"fromBlock": "0x" + strconv.FormatUint(r.FromBlock, 16),
+		"toBlock":   "latest",
+		"topics":    topics,
+	}
+
+	var logs []rpcLog
+	if err := r.call("eth_getLogs", []interface{}{filter}, &logs); err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// token returns the metadata of a token contract, querying the node the first time it is seen.
+func (r *RPC) token(contract string) (*model.Token, error) {
+	contract = strings.ToLower(contract)
+
+	r.mu.Lock()
+	token, ok := r.tokens[contract]
+	r.mu.Unlock()
+
+	if ok {
+		return token, nil
+	}
+
+	var symbol, decimals string
+
+	// not every token implements the optional metadata getters, so failures leave the fields blank
+	_ = r.call("eth_call", []interface{}{map[string]string{"to": contract, "data": symbolSelector}, "latest"}, &symbol)
+	_ = r.call("eth_call", []interface{}{map[string]string{"to": contract, "data": decimalsSelector}, "latest"}, &decimals)
+
+	token = &model.Token{
+		Contract: contract,
+		Symbol:   decodeABIString(symbol),
+		Decimals: uint8(hexToBig(decimals).Uint64()),
+	}
+
+	r.mu.Lock()
+	r.tokens[contract] = token
+	r.mu.Unlock()
+
+	return token, nil
+}
+
+// blockTime returns the unix timestamp of a block.
+func (r *RPC) blockTime(number uint64) (int64, error) {
+	r.mu.Lock()
+	t, ok := r.blockTimes[number]
+	r.mu.Unlock()
+
+	if ok {
+		return t, nil
+	}
+
+	var block struct {
+		Timestamp string `json:"timestamp"`
+	}
+
+	if err := r.call("eth_getBlockByNumber", []interface{}{"0x" + strconv.FormatUint(number, 16), false}, &block); err != nil {
+		return 0, err
+	}
+
+	t = hexToBig(block.Timestamp).Int64()
+
+	r.mu.Lock()
+	r.blockTimes[number] = t
+	r.mu.Unlock()
+
+	return t, nil
+}
+
+// addressTopic left-pads an address to a 32-byte log topic.
+func addressTopic(address string) string {
+	return "0x" + strings.Repeat("0", 24) + strings.ToLower(strings.TrimPrefix(address, "0x"))
+}
+
+// TransferParties returns the sender and recipient of the ERC-20 Transfer event log of topics, or false if topics
+// aren't those of an ERC-20 Transfer.
+func TransferParties(topics []string) (string, string, bool) {
+	// ERC-721 transfers share the event signature but index the token ID as well
+	if len(topics) != 3 || strings.ToLower(topics[0]) != transferTopic {
+		return "", "", false
+	}
+	return topicAddress(topics[1]), topicAddress(topics[2]), true
+}
+
+// topicAddress extracts the address from a 32-byte log topic.
+func topicAddress(topic string) string {
+	topic = strings.TrimPrefix(topic, "0x")
+	if len(topic) < 40 {
+		return ""
+	}
+	return "0x" + strings.ToLower(topic[len(topic)-40:])
+}
+
+func hexToBig(s string) *big.Int {
+	v, ok := new(big.Int).SetString(strings.TrimPrefix(s, "0x"), 16)
+	if !ok {
+		return new(big.Int)
+	}
+	return v
+}
+
+// decodeABIString decodes an ABI encoded string, falling back to a bytes32 for older tokens such as MKR.
+func decodeABIString(s string) string {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return ""
+	}
+
+	if len(b) >= 64 {
+		offset := new(big.Int).SetBytes(b[:32]).Uint64()
+		if offset+32 <= uint64(len(b)) {
+			length := new(big.Int).SetBytes(b[offset : offset+32]).Uint64()
+			if offset+32+length <= uint64(len(b)) {
+				return string(b[offset+32 : offset+32+length])
+			}
+		}
+	}
+
+	return string(bytes.TrimRight(b, "\x00"))
+}
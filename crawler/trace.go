@@ -0,0 +1,172 @@
+package crawler
+
+import (
+	"container/list"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/perlin-network/safu-go/model"
+)
+
+// callFrame is a call reported by geth's callTracer.
+type callFrame struct {
+	Type  string      `json:"type"`
+	From  string      `json:"from"`
+	To    string      `json:"to"`
+	Value string      `json:"value"`
+	Error string      `json:"error"`
+	Calls []callFrame `json:"calls"`
+}
+
+// InternalTransfers traces a transaction with debug_traceTransaction and returns the ether moved by the contracts it
+// called. The node must expose the debug API.
+func (r *RPC) InternalTransfers(txHash string) ([]*model.Edge, error) {
+	var root callFrame
+
+	if err := r.call("debug_traceTransaction", []interface{}{txHash, map[string]string{"tracer": "callTracer"}}, &root); err != nil {
+		return nil, err
+	}
+
+	// a reverted transaction undoes every transfer it made
+	if root.Error != "" {
+		return nil, nil
+	}
+
+	var edges []*model.Edge
+
+	var walk func(frames []callFrame, path string)
+	walk = func(frames []callFrame, path string) {
+		for i, f := range frames {
+			position := path + strconv.Itoa(i)
+
+			// a reverted call undoes its own transfers and those of its subcalls
+			if f.Error != "" {
+				continue
+			}
+
+			// delegate and static calls can't carry value
+			if f.Type != "DELEGATECALL" && f.Type != "STATICCALL" {
+				if value := hexToBig(f.Value); value.Sign() > 0 {
+					edge := model.NewEdge(r.chain, strings.ToLower(f.From), strings.ToLower(f.To), txHash, "")
+					edge.Amount = value.String()
+					edge.Internal = true
+					edge.Position = position
+
+					edges = append(edges, edge)
+				}
+			}
+
+			walk(f.Calls, position+"_")
+		}
+	}
+
+	walk(root.Calls, "")
+
+	return edges, nil
+}
+
+// tracedTransactions bounds the number of transactions whose internal transfers Traced keeps.
+const tracedTransactions = 10000
+
+type traced struct {
+	src    Source
+	tracer *RPC
+
+	mu     sync.Mutex
+	cached map[string]*list.Element
+	// recent orders the cached transactions from the most recently used, to evict the least recently used ones.
+	recent *list.List
+}
+
+// tracedTx is the internal transfers of a transaction.
+type tracedTx struct {
+	hash      string
+	transfers []*model.Edge
+}
+
+// Traced wraps src so that every transaction it returns is traced with tracer, adding the internal transfers made
+// through the contracts it called. Use it with sources that don't report internal transactions themselves: the
+// positions of the transfers traced may not match theirs, storing a transfer twice.
+func Traced(src Source, tracer *RPC) Source {
+	return &traced{
+		src:    src,
+		tracer: tracer,
+		cached: make(map[string]*list.Element),
+		recent: list.New(),
+	}
+}
+
+func (t *traced) Transfers(address string) ([]*model.Edge, error) {
+	edges, err := t.src.Transfers(address)
+	if err != nil {
+		return nil, err
+	}
+
+	var internal []*model.Edge
+
+	seen := make(map[string]bool)
+
+	for _, e := range edges {
+		if e.Internal || seen[e.TxHash] {
+			continue
+		}
+		seen[e.TxHash] = true
+
+		transfers, ok := t.get(e.TxHash)
+		if !ok {
+			transfers, err = t.tracer.InternalTransfers(e.TxHash)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, transfer := range transfers {
+				transfer.BlockNumber = e.BlockNumber
+				transfer.Timestamp = e.Timestamp
+			}
+
+			t.put(e.TxHash, transfers)
+		}
+
+		for _, transfer := range transfers {
+			// hand out copies, as the crawl sets the direction of the edges it follows
+			c := *transfer
+			internal = append(internal, &c)
+		}
+	}
+
+	return append(edges, internal...), nil
+}
+
+// get returns the cached internal transfers of the transaction txHash.
+func (t *traced) get(txHash string) ([]*model.Edge, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	elem, ok := t.cached[txHash]
+	if !ok {
+		return nil, false
+	}
+	t.recent.MoveToFront(elem)
+
+	return elem.Value.(*tracedTx).transfers, true
+}
+
+// put caches the internal transfers of the transaction txHash, evicting the least recently used transaction if
+// tracedTransactions are cached.
+func (t *traced) put(txHash string, transfers []*model.Edge) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.cached[txHash]; ok {
+		return
+	}
+
+	if t.recent.Len() >= tracedTransactions {
+		oldest := t.recent.Back()
+		t.recent.Remove(oldest)
+		delete(t.cached, oldest.Value.(*tracedTx).hash)
+	}
+
+	t.cached[txHash] = t.recent.PushFront(&tracedTx{hash: txHash, transfers: transfers})
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"encoding/json"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"time"
+)
+
+// AllowlistEntry marks an address as known to be good, e.g. an exchange's hot wallet or a treasury. Taint reaching
+// it is capped at the allowlist cap of the scoring policy and isn't passed on.
+type AllowlistEntry struct {
+	Chain   model.Chain `json:"chain"`
+	Address string      `json:"address"`
+	Reason  string      `json:"reason"`
+
+	// Actor is who allowlisted the address.
+	Actor string `json:"actor"`
+
+	// CreatedAt is the unix timestamp the address was allowlisted at, and ExpiresAt the one it stops being
+	// allowlisted at, or 0 if it doesn't expire.
+	CreatedAt int64 `json:"created_at"`
+	ExpiresAt int64 `json:"expires_at"`
+}
+
+// Expired returns whether the entry had expired at the unix timestamp now.
+func (e *AllowlistEntry) Expired(now int64) bool {
+	return e.ExpiresAt != 0 && e.ExpiresAt <= now
+}
+
+func allowlistKey(chain model.Chain, address string) []byte {
+	return []byte("allow_" + string(chain) + "_" + address)
+}
+
+// PutAllowlistEntry allowlists the address of e, replacing any previous entry of the address, and records it in the
+// audit trail.
+func (t *TieDotStore) PutAllowlistEntry(e *AllowlistEntry) error {
+	address, err := model.ParseAddress(e.Chain, e.Address)
+	if err != nil {
+		return err
+	}
+	e.Address = address.Key()
+
+	if e.Reason == "" {
+		return errors.New("allowlist entry has no reason")
+	}
+
+	e.CreatedAt = time.Now().Unix()
+	if e.ExpiresAt != 0 && e.ExpiresAt <= e.CreatedAt {
+		return errors.New("allowlist entry expires in the past")
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	batch := &leveldb.Batch{}
+	batch.Put(allowlistKey(e.Chain, e.Address), b)
+
+	err = putAuditEvent(batch, &AuditEvent{
+		Action:    AuditAllowlistAdd,
+		Chain:     e.Chain,
+		Address:   e.Address,
+		Actor:     e.Actor,
+		Reason:    e.Reason,
+		ExpiresAt: e.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return t.db.Write(batch, nil)
+}
+
+// DeleteAllowlistEntry removes address from the allowlist of chain, and records it in the audit trail. It returns
+// leveldb.ErrNotFound if the address isn't allowlisted.
+func (t *TieDotStore) DeleteAllowlistEntry(chain model.Chain, address string, actor string, reason string) error {
+	address = model.NormalizeAddress(chain, address)
+	key := allowlistKey(chain, address)
+
+	if _, err := t.db.Get(key, nil); err != nil {
+		return err
+	}
+
+	batch := &leveldb.Batch{}
+	batch.Delete(key)
+
+	err := putAuditEvent(batch, &AuditEvent{
+		Action:  AuditAllowlistRemove,
+		Chain:   chain,
+		Address: address,
+		Actor:   actor,
+		Reason:  reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	return t.db.Write(batch, nil)
+}
+
+// GetAllowlistEntry returns the allowlist entry of address on chain, or nil if it isn't allowlisted or its entry
+// expired.
+func (t *TieDotStore) GetAllowlistEntry(chain model.Chain, address string) (*AllowlistEntry, error) {
+	b, err := t.db.Get(allowlistKey(chain, model.NormalizeAddress(chain, address)), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var e AllowlistEntry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, err
+	}
+
+	if e.Expired(time.Now().Unix()) {
+		return nil, nil
+	}
+
+	return &e, nil
+}
+
+// ForEachAllowlistEntry calls callback with every allowlist entry of chain, or of every chain if chain is empty,
+// expired entries included.
+func (t *TieDotStore) ForEachAllowlistEntry(chain model.Chain, callback func(e *AllowlistEntry) error) error {
+	prefix := "allow_"
+	if chain != "" {
+		prefix += string(chain) + "_"
+	}
+
+	iter := t.db.NewIterator(util.BytesPrefix([]byte(prefix)), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		var e = &AllowlistEntry{}
+		if err := json.Unmarshal(iter.Value(), e); err != nil {
+			return err
+		}
+
+		if err := callback(e); err != nil {
+			return err
+		}
+	}
+
+	return iter.Error()
+}
+
+// allowlistCaps caps the taint of the allowlisted addresses among addresses at max.
+func (t *TieDotStore) allowlistCaps(chain model.Chain, addresses []string, max int) (map[string]int, error) {
+	caps := make(map[string]int)
+
+	for _, address := range addresses {
+		e, err := t.GetAllowlistEntry(chain, address)
+		if err != nil {
+			return nil, err
+		}
+
+		if e != nil {
+			caps[address] = max
+		}
+	}
+
+	return caps, nil
+}
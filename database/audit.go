@@ -0,0 +1,95 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"sync/atomic"
+	"time"
+)
+
+// AuditAction is what an audit event records.
+type AuditAction string
+
+const (
+	AuditAllowlistAdd    AuditAction = "allowlist_add"
+	AuditAllowlistRemove AuditAction = "allowlist_remove"
+
+	// AuditTaintSuppressed records taint that was capped, and not passed on, at an allowlisted address.
+	AuditTaintSuppressed AuditAction = "taint_suppressed"
+
+	// AuditReportDelete records the deletion of a report by an admin, AuditReportModerate a moderator changing the
+	// status of a report, and AuditReportDispute the holder of a reported address disputing a report.
+	AuditReportDelete   AuditAction = "report_delete"
+	AuditReportModerate AuditAction = "report_moderate"
+	AuditReportDispute  AuditAction = "report_dispute"
+)
+
+// AuditEvent is an entry of the audit trail.
+type AuditEvent struct {
+	ID     string      `json:"id"`
+	Time   int64       `json:"time"`
+	Action AuditAction `json:"action"`
+
+	Chain   model.Chain `json:"chain"`
+	Address string      `json:"address"`
+
+	// Actor and Reason are who made an allowlist change, or deleted, moderated or disputed a report, and why.
+	Actor  string `json:"actor,omitempty"`
+	Reason string `json:"reason,omitempty"`
+
+	// ExpiresAt is the expiry of an added allowlist entry.
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+
+	// Source is the reported address whose taint was suppressed, Taint the taint the allowlisted address would have
+	// held, and Cap the taint it was capped at.
+	Source string `json:"source,omitempty"`
+	Taint  int    `json:"taint,omitempty"`
+	Cap    int    `json:"cap,omitempty"`
+
+	// ReportID is the deleted, moderated or disputed report, and Status the status it was moved to.
+	ReportID string       `json:"report_id,omitempty"`
+	Status   ReportStatus `json:"status,omitempty"`
+}
+
+var auditSequence uint32
+
+// putAuditEvent adds e to batch. Events are keyed by time, so the trail is iterated in order.
+func putAuditEvent(batch *leveldb.Batch, e *AuditEvent) error {
+	now := time.Now()
+
+	e.Time = now.Unix()
+	e.ID = fmt.Sprintf("%020d%010d", now.UnixNano(), atomic.AddUint32(&auditSequence, 1))
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	batch.Put([]byte("audit_"+e.ID), b)
+	return nil
+}
+
+// ForEachAuditEvent calls callback with every event of the audit trail since the unix timestamp since, oldest first.
+func (t *TieDotStore) ForEachAuditEvent(since int64, callback func(e *AuditEvent) error) error {
+	r := util.BytesPrefix([]byte("audit_"))
+	r.Start = []byte(fmt.Sprintf("audit_%020d", time.Unix(since, 0).UnixNano()))
+
+	iter := t.db.NewIterator(r, nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		var e = &AuditEvent{}
+		if err := json.Unmarshal(iter.Value(), e); err != nil {
+			return err
+		}
+
+		if err := callback(e); err != nil {
+			return err
+		}
+	}
+
+	return iter.Error()
+}
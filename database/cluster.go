@@ -0,0 +1,160 @@
+package database
+
+import (
+	"encoding/json"
+	"github.com/perlin-network/safu-go/cluster"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// ClusterMember assigns an address to the cluster of addresses controlled by the same entity, with the evidence
+// that links the address to other members.
+type ClusterMember struct {
+	Chain     model.Chain         `json:"chain"`
+	Address   string              `json:"address"`
+	ClusterID string              `json:"cluster_id"`
+	Evidence  []*cluster.Evidence `json:"evidence"`
+}
+
+func clusterKey(chain model.Chain, address string) []byte {
+	return []byte("cluster_" + string(chain) + "_" + address)
+}
+
+func entityKey(chain model.Chain, id string) []byte {
+	return []byte("entity_" + string(chain) + "_" + id)
+}
+
+// InsertClusters merges clusters into the stored clusters of chain. Clusters sharing an address are merged, and the
+// evidence of both is kept.
+func (t *TieDotStore) InsertClusters(chain model.Chain, clusters []*cluster.Cluster) error {
+	stored, err := t.GetClusters(chain)
+	if err != nil {
+		return err
+	}
+
+	c := cluster.New()
+	c.Add(stored...)
+	c.Add(clusters...)
+
+	batch := &leveldb.Batch{}
+
+	// merged clusters may be renamed, so every cluster of the chain is rewritten
+	for _, prefix := range []string{"cluster_", "entity_"} {
+		iter := t.db.NewIterator(util.BytesPrefix([]byte(prefix+string(chain)+"_")), nil)
+		for iter.Next() {
+			batch.Delete(append([]byte{}, iter.Key()...))
+		}
+		iter.Release()
+
+		if err := iter.Error(); err != nil {
+			return err
+		}
+	}
+
+	for _, cl := range c.Clusters() {
+		if err := putCluster(batch, chain, cl); err != nil {
+			return err
+		}
+	}
+
+	return t.db.Write(batch, nil)
+}
+
+func putCluster(batch *leveldb.Batch, chain model.Chain, cl *cluster.Cluster) error {
+	b, err := json.Marshal(cl)
+	if err != nil {
+		return err
+	}
+	batch.Put(entityKey(chain, cl.ID), b)
+
+	for _, address := range cl.Members {
+		b, err := json.Marshal(&ClusterMember{
+			Chain:     chain,
+			Address:   address,
+			ClusterID: cl.ID,
+			Evidence:  cl.EvidenceFor(address),
+		})
+		if err != nil {
+			return err
+		}
+
+		batch.Put(clusterKey(chain, address), b)
+	}
+
+	return nil
+}
+
+// GetClusters returns the clusters of chain.
+func (t *TieDotStore) GetClusters(chain model.Chain) ([]*cluster.Cluster, error) {
+	var clusters []*cluster.Cluster
+
+	iter := t.db.NewIterator(util.BytesPrefix([]byte("entity_"+string(chain)+"_")), nil)
+
+	for iter.Next() {
+		var cl = &cluster.Cluster{}
+		if err := json.Unmarshal(iter.Value(), cl); err != nil {
+			iter.Release()
+			return nil, err
+		}
+
+		clusters = append(clusters, cl)
+	}
+
+	iter.Release()
+	return clusters, iter.Error()
+}
+
+// GetCluster returns the cluster address belongs to, or nil if it isn't clustered with any other address.
+func (t *TieDotStore) GetCluster(chain model.Chain, address string) (*ClusterMember, error) {
+	b, err := t.db.Get(clusterKey(chain, model.NormalizeAddress(chain, address)), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m ClusterMember
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// GetEntity returns the cluster identified by id on chain.
+func (t *TieDotStore) GetEntity(chain model.Chain, id string) (*cluster.Cluster, error) {
+	b, err := t.db.Get(entityKey(chain, id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var cl cluster.Cluster
+	if err := json.Unmarshal(b, &cl); err != nil {
+		return nil, err
+	}
+
+	return &cl, nil
+}
+
+// EntityMembers returns the addresses of the entity address belongs to: the members of its cluster, or the address
+// alone if it isn't clustered.
+func (t *TieDotStore) EntityMembers(chain model.Chain, address string) ([]string, error) {
+	address = model.NormalizeAddress(chain, address)
+
+	m, err := t.GetCluster(chain, address)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return []string{address}, nil
+	}
+
+	cl, err := t.GetEntity(chain, m.ClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return cl.Members, nil
+}
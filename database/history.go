@@ -0,0 +1,118 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// ScoreSnapshot is the taint score an address was computed to hold at a point in time, with its components.
+type ScoreSnapshot struct {
+	Chain   model.Chain `json:"chain"`
+	Address string      `json:"address"`
+
+	// Time is the unix timestamp the score was computed at.
+	Time int64 `json:"time"`
+
+	// PolicyVersion is the version of the scoring policy the score was computed under.
+	PolicyVersion string `json:"policy_version"`
+
+	Score    int    `json:"score"`
+	RiskBand string `json:"risk_band"`
+
+	Reports    float64 `json:"reports"`
+	Reputation float64 `json:"reputation"`
+	GraphRisk  float64 `json:"graph_risk"`
+
+	Sanctioned  bool `json:"sanctioned"`
+	Allowlisted bool `json:"allowlisted"`
+}
+
+// same returns whether s and o hold the same score, computed the same way. The components of the scores aren't
+// compared: they decay continuously, and would otherwise make every snapshot differ from the previous one.
+func (s *ScoreSnapshot) same(o *ScoreSnapshot) bool {
+	return s.PolicyVersion == o.PolicyVersion &&
+		s.Score == o.Score &&
+		s.RiskBand == o.RiskBand &&
+		s.Sanctioned == o.Sanctioned &&
+		s.Allowlisted == o.Allowlisted
+}
+
+// clean returns whether s holds the score of an address nothing is known about.
+func (s *ScoreSnapshot) clean() bool {
+	return s.Score == 0 && !s.Sanctioned && !s.Allowlisted
+}
+
+func scoreSnapshotPrefix(chain model.Chain, address string) []byte {
+	return []byte("history_" + string(chain) + "_" + address + "_")
+}
+
+func scoreSnapshotKey(chain model.Chain, address string, time int64) []byte {
+	return append(scoreSnapshotPrefix(chain, address), fmt.Sprintf("%020d", time)...)
+}
+
+// PutScoreSnapshot adds s to the score history of its address. It is a no-op if the latest snapshot of the address
+// holds the same score, so that the history only records changes, or if the address has no history and s is clean, so
+// that querying addresses nothing is known about records nothing.
+func (t *TieDotStore) PutScoreSnapshot(s *ScoreSnapshot) error {
+	s.Address = model.NormalizeAddress(s.Chain, s.Address)
+
+	latest, err := t.latestScoreSnapshot(s.Chain, s.Address)
+	if err != nil {
+		return err
+	}
+	if latest == nil && s.clean() || latest != nil && latest.same(s) {
+		return nil
+	}
+
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	return t.db.Put(scoreSnapshotKey(s.Chain, s.Address, s.Time), b, nil)
+}
+
+func (t *TieDotStore) latestScoreSnapshot(chain model.Chain, address string) (*ScoreSnapshot, error) {
+	iter := t.db.NewIterator(util.BytesPrefix(scoreSnapshotPrefix(chain, address)), nil)
+	defer iter.Release()
+
+	if !iter.Last() {
+		return nil, iter.Error()
+	}
+
+	var s = &ScoreSnapshot{}
+	if err := json.Unmarshal(iter.Value(), s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// GetScoreHistory returns the score snapshots of address on chain taken from the unix timestamp from to the unix
+// timestamp to, oldest first. A to of 0 doesn't bound the history.
+func (t *TieDotStore) GetScoreHistory(chain model.Chain, address string, from int64, to int64) ([]*ScoreSnapshot, error) {
+	address = model.NormalizeAddress(chain, address)
+
+	r := util.BytesPrefix(scoreSnapshotPrefix(chain, address))
+	r.Start = scoreSnapshotKey(chain, address, from)
+	if to > 0 {
+		r.Limit = scoreSnapshotKey(chain, address, to+1)
+	}
+
+	iter := t.db.NewIterator(r, nil)
+	defer iter.Release()
+
+	snapshots := []*ScoreSnapshot{}
+	for iter.Next() {
+		var s = &ScoreSnapshot{}
+		if err := json.Unmarshal(iter.Value(), s); err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, s)
+	}
+
+	return snapshots, iter.Error()
+}
@@ -0,0 +1,196 @@
+package database
+
+import (
+	"encoding/json"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/perlin-network/safu-go/policy"
+	"github.com/syndtr/goleveldb/leveldb"
+	"sort"
+)
+
+// ChangeSet is a change to what the taint of a chain is computed from: edges added to its graph, e.g. by an
+// incremental crawl, and newly reported addresses.
+type ChangeSet struct {
+	Chain   model.Chain
+	Edges   []*model.Edge
+	Sources []string
+}
+
+// ApplyChanges stores the edges of cs, and updates taint in the region of the graph cs affects only: taint is spread
+// again from the new sources and from the reported addresses whose funds reach the sender of a new edge, and merged
+// into the stored taint, every address keeping the highest taint it gets in each asset.
+//
+// Edges and reports only ever add taint, so if the stored taint is that of a full recomputation (RecomputeTaint),
+// it still is after the changes are applied. Sources are only spread from if a report of a status the policy p
+// weighs reports them. A recomputation running meanwhile spreads taint again from the same sources before swapping
+// its results in.
+func (t *TieDotStore) ApplyChanges(cs *ChangeSet, p *policy.Policy) error {
+	t.taintMu.Lock()
+	defer t.taintMu.Unlock()
+
+	graph := model.NewGraph(cs.Chain)
+	for _, address := range cs.Sources {
+		graph.AddVertex(model.NormalizeAddress(cs.Chain, address))
+	}
+	for _, e := range cs.Edges {
+		graph.AddEdge(e)
+	}
+
+	if err := t.InsertGraph(graph); err != nil {
+		return err
+	}
+
+	reported, reports, err := t.reportIndex(cs.Chain, p)
+	if err != nil {
+		return err
+	}
+
+	affected := make(map[string]bool)
+	for _, address := range cs.Sources {
+		address = model.NormalizeAddress(cs.Chain, address)
+
+		if reported[address] {
+			// only the new sources record the taint they suppress, so that spreading taint again doesn't repeat it
+			affected[address] = true
+		}
+	}
+
+	// the funds of a reported address reach a new edge if the address reaches its sender, or is in the same entity
+	// as an address that does. The senders' upstream is loaded in one pass, each address being visited once.
+	senders := make([]string, 0, len(cs.Edges))
+	for _, e := range cs.Edges {
+		senders = append(senders, e.From)
+	}
+
+	if len(senders) > 0 {
+		upstream, _, err := t.loadGraph(cs.Chain, senders, model.DirectionBackward, -1, true)
+		if err != nil {
+			return err
+		}
+
+		for _, u := range upstream.Addresses() {
+			if _, ok := affected[u]; !ok && reported[u] {
+				affected[u] = false
+			}
+		}
+	}
+
+	sources := make([]string, 0, len(affected))
+	for address := range affected {
+		sources = append(sources, address)
+	}
+	sort.Strings(sources)
+
+	batch := &leveldb.Batch{}
+	if err := t.markDirty(batch, cs.Chain, sources); err != nil {
+		return err
+	}
+
+	scores := make(map[string]int)
+	merged := make(map[string]*Taint)
+
+	for _, source := range sources {
+		graph, res, caps, err := t.propagate(cs.Chain, source, 100, p)
+		if err != nil {
+			return err
+		}
+
+		for u, score := range res.Scores {
+			if score > scores[u] {
+				scores[u] = score
+			}
+		}
+
+		for _, a := range res.Assets {
+			key := taintKey(cs.Chain, a.Address, a.Asset)
+			next := &Taint{Chain: cs.Chain, Address: a.Address, Asset: a.Asset, Symbol: a.Symbol, Score: a.Score, Since: a.Since}
+
+			current, ok := merged[string(key)]
+			if !ok {
+				if current, err = t.getTaint(key); err != nil {
+					return err
+				}
+			}
+
+			if current == nil || next.outweighs(current) {
+				merged[string(key)] = next
+			}
+		}
+
+		if !affected[source] {
+			continue
+		}
+
+		for _, u := range graph.Addresses() {
+			suppressed, ok := res.Suppressed[u]
+			if !ok {
+				continue
+			}
+
+			err := putAuditEvent(batch, &AuditEvent{
+				Action:  AuditTaintSuppressed,
+				Chain:   cs.Chain,
+				Address: u,
+				Source:  source,
+				Taint:   suppressed,
+				Cap:     caps[u],
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for key, taint := range merged {
+		b, err := json.Marshal(taint)
+		if err != nil {
+			return err
+		}
+
+		batch.Put([]byte(key), b)
+	}
+
+	for address, score := range scores {
+		for _, r := range reports[address] {
+			if r.Taint >= score {
+				continue
+			}
+			r.Taint = score
+
+			b, err := json.Marshal(r)
+			if err != nil {
+				return err
+			}
+
+			batch.Put([]byte("report_"+r.ID), b)
+		}
+	}
+
+	return t.db.Write(batch, nil)
+}
+
+// reportIndex returns the addresses of chain reported by a report of a status p weighs, and the reports against
+// every reported address of chain, whatever their status.
+func (t *TieDotStore) reportIndex(chain model.Chain, p *policy.Policy) (map[string]bool, map[string][]*Report, error) {
+	reported := make(map[string]bool)
+	reports := make(map[string][]*Report)
+
+	err := t.ForEachReport(func(r *Report) error {
+		if r.Chain != chain {
+			return nil
+		}
+
+		address := model.NormalizeAddress(chain, r.ScammerAddress)
+		reports[address] = append(reports[address], r)
+
+		if p.ReportStatus[string(r.Status)] > 0 {
+			reported[address] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reported, reports, nil
+}
@@ -0,0 +1,261 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/perlin-network/safu-go/cluster"
+	"github.com/perlin-network/safu-go/label"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/perlin-network/safu-go/policy"
+)
+
+// randomHistory applies random changes to a store, the way the API does: reports and crawled edges only add taint
+// and are applied incrementally, while retracting reports and changing labels or the allowlist may remove taint, and
+// have it recomputed.
+type randomHistory struct {
+	t     *testing.T
+	rng   *rand.Rand
+	store *TieDotStore
+	p     *policy.Policy
+
+	addresses []string
+	reports   []*Report
+	token     *model.Token
+	step      int
+
+	// labeled and allowlisted are the addresses labeled and allowlisted so far, to end their labels and entries.
+	labeled     []string
+	allowlisted []string
+}
+
+func (h *randomHistory) address() string {
+	return h.addresses[h.rng.Intn(len(h.addresses))]
+}
+
+func (h *randomHistory) apply(cs *ChangeSet) {
+	cs.Chain = model.ChainEthereum
+	if err := h.store.ApplyChanges(cs, h.p); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+func (h *randomHistory) recompute() {
+	if _, err := h.store.RecomputeTaint(h.p, true, nil); err != nil {
+		h.t.Fatal(err)
+	}
+}
+
+func (h *randomHistory) edges() []*model.Edge {
+	var edges []*model.Edge
+
+	for i := h.rng.Intn(4); i > 0; i-- {
+		e := model.NewEdge(model.ChainEthereum, h.address(), h.address(), fmt.Sprintf("0x%x", h.rng.Int63()), model.DirectionForward)
+		e.Timestamp = int64(h.rng.Intn(100))
+		e.Amount = "1"
+		if h.rng.Intn(3) == 0 {
+			e.Token = h.token
+		}
+
+		edges = append(edges, e)
+	}
+
+	return edges
+}
+
+// change applies a random change, and returns its name.
+func (h *randomHistory) change() string {
+	chain := model.ChainEthereum
+
+	switch h.rng.Intn(9) {
+	case 0, 1:
+		scammer := h.address()
+
+		id, err := h.store.AddReport(Report{Chain: chain, ScammerAddress: scammer, VictimAddress: h.address(), Timestamp: int64(h.step)})
+		if err != nil {
+			h.t.Fatal(err)
+		}
+
+		r, err := h.store.GetReport(id)
+		if err != nil {
+			h.t.Fatal(err)
+		}
+		h.reports = append(h.reports, r)
+
+		h.apply(&ChangeSet{Sources: []string{scammer}, Edges: h.edges()})
+		return "report " + scammer
+
+	case 2, 3:
+		h.apply(&ChangeSet{Edges: h.edges()})
+		return "crawl"
+
+	case 4:
+		if len(h.reports) == 0 {
+			return "nothing"
+		}
+
+		i := h.rng.Intn(len(h.reports))
+		r, err := h.store.GetReport(h.reports[i].ID)
+		if err != nil {
+			h.t.Fatal(err)
+		}
+		h.reports = append(h.reports[:i], h.reports[i+1:]...)
+
+		r.Status = ReportRetracted
+		if err := h.store.UpdateReport(r, &ReportVersion{Action: ReportActionRetract}); err != nil {
+			h.t.Fatal(err)
+		}
+
+		h.recompute()
+		return "retract report of " + r.ScammerAddress
+
+	case 5:
+		address := h.address()
+
+		// labels below the policy's confidence don't stop taint, and don't need a recomputation
+		l := &label.Label{Chain: chain, Address: address, Category: label.CategoryExchange, Name: "exchange", Source: "test", Confidence: 1}
+		if h.rng.Intn(2) == 0 {
+			l.Confidence = h.p.Propagation.MinLabelConfidence / 2
+		}
+
+		if err := h.store.PutLabels(l); err != nil {
+			h.t.Fatal(err)
+		}
+		h.labeled = append(h.labeled, address)
+
+		if l.Confidence >= h.p.Propagation.MinLabelConfidence {
+			h.recompute()
+		}
+		return fmt.Sprintf("label %s with confidence %v", address, l.Confidence)
+
+	case 6:
+		if len(h.labeled) == 0 {
+			return "nothing"
+		}
+		address := h.labeled[h.rng.Intn(len(h.labeled))]
+
+		if err := h.store.DeleteLabel(chain, address, "test"); err != nil {
+			return "nothing"
+		}
+
+		h.recompute()
+		return "unlabel " + address
+
+	case 7:
+		address := h.address()
+
+		e := &AllowlistEntry{Chain: chain, Address: address, Reason: "test"}
+		if h.rng.Intn(2) == 0 {
+			e.ExpiresAt = time.Now().Add(time.Hour).Unix()
+		}
+
+		if err := h.store.PutAllowlistEntry(e); err != nil {
+			h.t.Fatal(err)
+		}
+		h.allowlisted = append(h.allowlisted, address)
+
+		h.recompute()
+		return "allowlist " + address
+
+	default:
+		if len(h.allowlisted) == 0 {
+			return "nothing"
+		}
+		address := h.allowlisted[h.rng.Intn(len(h.allowlisted))]
+
+		e, err := h.store.GetAllowlistEntry(chain, address)
+		if err != nil {
+			h.t.Fatal(err)
+		}
+		if e == nil {
+			return "nothing"
+		}
+
+		// the entry expires or is removed
+		if h.rng.Intn(2) == 0 {
+			e.ExpiresAt = time.Now().Add(-time.Second).Unix()
+
+			b, err := json.Marshal(e)
+			if err != nil {
+				h.t.Fatal(err)
+			}
+			if err := h.store.db.Put(allowlistKey(chain, e.Address), b, nil); err != nil {
+				h.t.Fatal(err)
+			}
+		} else if err := h.store.DeleteAllowlistEntry(chain, address, "test", "test"); err != nil {
+			h.t.Fatal(err)
+		}
+
+		h.recompute()
+		return "end the allowlisting of " + address
+	}
+}
+
+// TestApplyChangesEqualsRecomputation checks, for random histories of changes, that the taint updated incrementally
+// after every change equals a full recomputation.
+func TestApplyChangesEqualsRecomputation(t *testing.T) {
+	rounds := 100
+	if testing.Short() {
+		rounds = 10
+	}
+
+	p := policy.Default()
+	rng := rand.New(rand.NewSource(1))
+
+	for round := 0; round < rounds; round++ {
+		h := &randomHistory{
+			t:     t,
+			rng:   rng,
+			store: newTestStore(t),
+			p:     p,
+			token: &model.Token{Contract: testAddress(0xdead), Symbol: "TKN", Decimals: 18},
+		}
+
+		h.addresses = make([]string, 4+rng.Intn(12))
+		for i := range h.addresses {
+			h.addresses[i] = testAddress(i + 1)
+		}
+
+		// entities aren't part of change sets, so they are set up before any change
+		clusterer := cluster.New()
+		for i := rng.Intn(3); i > 0; i-- {
+			clusterer.Link(&cluster.Evidence{Heuristic: cluster.HeuristicSharedFunding, Addresses: [2]string{h.address(), h.address()}})
+		}
+		if err := h.store.InsertClusters(model.ChainEthereum, clusterer.Clusters()); err != nil {
+			t.Fatal(err)
+		}
+
+		var changes []string
+		for steps := 1 + rng.Intn(15); h.step < steps; h.step++ {
+			changes = append(changes, h.change())
+
+			mismatches, err := h.store.VerifyTaint(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(mismatches) > 0 {
+				m := mismatches[0]
+				t.Fatalf("round %d: after %v, taint differs from a full recomputation in %d places, e.g. %s %s report %s: %d since %d, recomputed %d since %d",
+					round, changes, len(mismatches), m.Address, m.Asset, m.ReportID, m.Stored, m.StoredSince, m.Recomputed, m.RecomputedSince)
+			}
+		}
+
+		// recomputing taint that equals a full recomputation changes nothing
+		before := storedTaint(t, h.store)
+		h.recompute()
+		after := storedTaint(t, h.store)
+
+		for key := range before {
+			if before[key] != after[key] {
+				t.Fatalf("round %d: after %v, recomputing changed %s from %s to %s", round, changes, key, before[key], after[key])
+			}
+		}
+		if len(before) != len(after) {
+			t.Fatalf("round %d: after %v, recomputing changed the number of stored taints from %d to %d", round, changes, len(before), len(after))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"encoding/json"
+	"github.com/perlin-network/safu-go/label"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"time"
+)
+
+func labelPrefix(chain model.Chain, address string) []byte {
+	return []byte("label_" + string(chain) + "_" + address + "_")
+}
+
+func labelKey(chain model.Chain, address string, source string) []byte {
+	return append(labelPrefix(chain, address), source...)
+}
+
+// PutLabels stores labels, replacing the labels of their addresses from the same sources.
+func (t *TieDotStore) PutLabels(labels ...*label.Label) error {
+	batch := &leveldb.Batch{}
+	now := time.Now().Unix()
+
+	for _, l := range labels {
+		if err := l.Normalize(); err != nil {
+			return err
+		}
+		l.UpdatedAt = now
+
+		b, err := json.Marshal(l)
+		if err != nil {
+			return err
+		}
+
+		batch.Put(labelKey(l.Chain, l.Address, l.Source), b)
+	}
+
+	return t.db.Write(batch, nil)
+}
+
+// DeleteLabel deletes the label of address on chain from source.
+func (t *TieDotStore) DeleteLabel(chain model.Chain, address string, source string) error {
+	key := labelKey(chain, model.NormalizeAddress(chain, address), source)
+
+	if _, err := t.db.Get(key, nil); err != nil {
+		return err
+	}
+
+	return t.db.Delete(key, nil)
+}
+
+// GetLabels returns the labels of address on chain.
+func (t *TieDotStore) GetLabels(chain model.Chain, address string) ([]*label.Label, error) {
+	var labels []*label.Label
+
+	err := t.forEachLabel(labelPrefix(chain, model.NormalizeAddress(chain, address)), func(l *label.Label) error {
+		labels = append(labels, l)
+		return nil
+	})
+
+	return labels, err
+}
+
+// ForEachLabel calls callback with every label stored on chain, or on every chain if chain is empty.
+func (t *TieDotStore) ForEachLabel(chain model.Chain, callback func(l *label.Label) error) error {
+	prefix := "label_"
+	if chain != "" {
+		prefix += string(chain) + "_"
+	}
+
+	return t.forEachLabel([]byte(prefix), callback)
+}
+
+func (t *TieDotStore) forEachLabel(prefix []byte, callback func(l *label.Label) error) error {
+	iter := t.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		var l = &label.Label{}
+		if err := json.Unmarshal(iter.Value(), l); err != nil {
+			return err
+		}
+
+		if err := callback(l); err != nil {
+			return err
+		}
+	}
+
+	return iter.Error()
+}
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/perlin-network/safu-go/label"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"sort"
+	"time"
+)
+
+// ListVersion records an import of an address list, e.g. a sanctions list or a blocklist, into the labels of its
+// source, with what the import changed.
+type ListVersion struct {
+	Source  string `json:"source"`
+	Version int    `json:"version"`
+
+	// ImportedAt is the unix timestamp of the import.
+	ImportedAt int64 `json:"imported_at"`
+
+	// Entries is the number of addresses on the list.
+	Entries int `json:"entries"`
+
+	// Added and Removed hold the addresses the import added to and removed from the previous version of the list.
+	Added   []*ListEntry `json:"added"`
+	Removed []*ListEntry `json:"removed"`
+}
+
+// ListEntry is an address of a list.
+type ListEntry struct {
+	Chain   model.Chain `json:"chain"`
+	Address string      `json:"address"`
+}
+
+func listVersionPrefix(source string) []byte {
+	return []byte("listversion_" + source + "_")
+}
+
+func listVersionKey(source string, version int) []byte {
+	return append(listVersionPrefix(source), fmt.Sprintf("%010d", version)...)
+}
+
+// ImportList replaces the labels of source with labels, the full contents of a new version of the list source
+// publishes, and records the version with the addresses it added and removed.
+func (t *TieDotStore) ImportList(source string, labels []*label.Label) (*ListVersion, error) {
+	versions, err := t.GetListVersions(source)
+	if err != nil {
+		return nil, err
+	}
+
+	version := &ListVersion{
+		Source:     source,
+		Version:    1,
+		ImportedAt: time.Now().Unix(),
+		Entries:    len(labels),
+		Added:      []*ListEntry{},
+		Removed:    []*ListEntry{},
+	}
+	if len(versions) > 0 {
+		version.Version = versions[len(versions)-1].Version + 1
+	}
+
+	previous := make(map[string]*label.Label)
+	err = t.ForEachLabel("", func(l *label.Label) error {
+		if l.Source == source {
+			previous[string(labelKey(l.Chain, l.Address, l.Source))] = l
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	batch := &leveldb.Batch{}
+
+	for _, l := range labels {
+		l.Source = source
+		if err := l.Normalize(); err != nil {
+			return nil, err
+		}
+		l.UpdatedAt = version.ImportedAt
+
+		b, err := json.Marshal(l)
+		if err != nil {
+			return nil, err
+		}
+
+		key := labelKey(l.Chain, l.Address, l.Source)
+		batch.Put(key, b)
+
+		if _, ok := previous[string(key)]; ok {
+			delete(previous, string(key))
+		} else {
+			version.Added = append(version.Added, &ListEntry{Chain: l.Chain, Address: model.DisplayAddress(l.Chain, l.Address)})
+		}
+	}
+
+	for key, l := range previous {
+		batch.Delete([]byte(key))
+		version.Removed = append(version.Removed, &ListEntry{Chain: l.Chain, Address: model.DisplayAddress(l.Chain, l.Address)})
+	}
+
+	sortListEntries(version.Added)
+	sortListEntries(version.Removed)
+
+	b, err := json.Marshal(version)
+	if err != nil {
+		return nil, err
+	}
+	batch.Put(listVersionKey(source, version.Version), b)
+
+	if err := t.db.Write(batch, nil); err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+func sortListEntries(entries []*ListEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Chain != entries[j].Chain {
+			return entries[i].Chain < entries[j].Chain
+		}
+		return entries[i].Address < entries[j].Address
+	})
+}
+
+// GetListVersions returns the versions of the list imported from source, oldest first.
+func (t *TieDotStore) GetListVersions(source string) ([]*ListVersion, error) {
+	var versions []*ListVersion
+
+	iter := t.db.NewIterator(util.BytesPrefix(listVersionPrefix(source)), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		var v = &ListVersion{}
+		if err := json.Unmarshal(iter.Value(), v); err != nil {
+			return nil, err
+		}
+
+		// the prefix of a source also matches the sources it prefixes, such as ofac_test for ofac
+		if v.Source != source {
+			continue
+		}
+
+		versions = append(versions, v)
+	}
+
+	return versions, iter.Error()
+}
@@ -0,0 +1,237 @@
+package database
+
+import (
+	"encoding/json"
+	"github.com/perlin-network/safu-go/cluster"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// migrateChainKeys moves vertices, edges and taints stored before keys were namespaced by chain under the Ethereum
+// namespace, and marks reports submitted before then as Ethereum reports. It is a no-op on migrated databases.
+func (t *TieDotStore) migrateChainKeys() error {
+	batch := &leveldb.Batch{}
+
+	migrate := func(prefix string, update func(value []byte) ([]byte, []byte, error)) error {
+		iter := t.db.NewIterator(util.BytesPrefix([]byte(prefix)), nil)
+		defer iter.Release()
+
+		for iter.Next() {
+			key, value, err := update(iter.Value())
+			if err != nil {
+				return err
+			}
+
+			batch.Delete(append([]byte{}, iter.Key()...))
+			batch.Put(key, value)
+		}
+
+		return iter.Error()
+	}
+
+	// Ethereum addresses start with 0x, whereas migrated keys start with the chain
+	err := migrate("addr_0x", func(value []byte) ([]byte, []byte, error) {
+		var v model.Vertex
+		if err := json.Unmarshal(value, &v); err != nil {
+			return nil, nil, err
+		}
+		v.Chain = model.ChainEthereum
+
+		b, err := json.Marshal(&v)
+		return vertexKey(v.Chain, v.Address), b, err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = migrate("edge_0x", func(value []byte) ([]byte, []byte, error) {
+		var e model.Edge
+		if err := json.Unmarshal(value, &e); err != nil {
+			return nil, nil, err
+		}
+		e.Chain = model.ChainEthereum
+
+		b, err := json.Marshal(&e)
+		return []byte("edge_" + e.Key()), b, err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = migrate("taint_0x", func(value []byte) ([]byte, []byte, error) {
+		var taint Taint
+		if err := json.Unmarshal(value, &taint); err != nil {
+			return nil, nil, err
+		}
+		taint.Chain = model.ChainEthereum
+		if taint.Asset == "ETH" {
+			taint.Asset = model.AssetNative
+		}
+
+		b, err := json.Marshal(&taint)
+		return taintKey(taint.Chain, taint.Address, taint.Asset), b, err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = t.ForEachReport(func(report *Report) error {
+		if report.Chain != "" {
+			return nil
+		}
+		report.Chain = model.ChainEthereum
+
+		b, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+
+		batch.Put([]byte("report_"+report.ID), b)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return t.db.Write(batch, nil)
+}
+
+// migrateClusterEntities stores the clusters of addresses assigned to a cluster before clusters were stored with
+// their evidence. Such addresses could only be clustered by the common-input-ownership heuristic, whose transactions
+// weren't recorded. It is a no-op on migrated databases.
+func (t *TieDotStore) migrateClusterEntities() error {
+	clusters := make(map[model.Chain]map[string]*cluster.Cluster)
+
+	iter := t.db.NewIterator(util.BytesPrefix([]byte("cluster_")), nil)
+	for iter.Next() {
+		var m ClusterMember
+		if err := json.Unmarshal(iter.Value(), &m); err != nil {
+			iter.Release()
+			return err
+		}
+
+		if m.Evidence != nil {
+			continue
+		}
+
+		if clusters[m.Chain] == nil {
+			clusters[m.Chain] = make(map[string]*cluster.Cluster)
+		}
+
+		cl, ok := clusters[m.Chain][m.ClusterID]
+		if !ok {
+			cl = &cluster.Cluster{ID: m.ClusterID}
+			clusters[m.Chain][m.ClusterID] = cl
+		}
+
+		if m.Address != m.ClusterID {
+			cl.Evidence = append(cl.Evidence, &cluster.Evidence{
+				Heuristic: cluster.HeuristicCommonInput,
+				Addresses: [2]string{m.ClusterID, m.Address},
+			})
+		}
+	}
+	iter.Release()
+
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	for chain, byID := range clusters {
+		var list []*cluster.Cluster
+		for _, cl := range byID {
+			list = append(list, cl)
+		}
+
+		if err := t.InsertClusters(chain, list); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateReportStatus marks reports submitted before reports had a status as submitted. It is a no-op on migrated
+// databases.
+func (t *TieDotStore) migrateReportStatus() error {
+	batch := &leveldb.Batch{}
+
+	err := t.ForEachReport(func(report *Report) error {
+		if report.Status != "" {
+			return nil
+		}
+		report.Status = ReportSubmitted
+
+		b, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+
+		batch.Put([]byte("report_"+report.ID), b)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return t.db.Write(batch, nil)
+}
+
+// migrateReportVersions records the reports submitted before reports were versioned as their first version. It is a
+// no-op on migrated databases.
+func (t *TieDotStore) migrateReportVersions() error {
+	batch := &leveldb.Batch{}
+
+	err := t.ForEachReport(func(report *Report) error {
+		if report.Version != 0 {
+			return nil
+		}
+		report.Version = 1
+
+		return putReport(batch, report, &ReportVersion{
+			Action:    ReportActionSubmit,
+			Time:      report.ReportedAt(),
+			Actor:     report.AccountID,
+			Signature: report.Proof,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return t.db.Write(batch, nil)
+}
+
+// migrateSubmitTimes corrects the time of the submissions of reports timestamped in microseconds or nanoseconds,
+// recorded before ReportedAt told them from milliseconds. It is a no-op on migrated databases.
+func (t *TieDotStore) migrateSubmitTimes() error {
+	batch := &leveldb.Batch{}
+
+	iter := t.db.NewIterator(util.BytesPrefix([]byte("reportversion_")), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		var v ReportVersion
+		if err := json.Unmarshal(iter.Value(), &v); err != nil {
+			return err
+		}
+
+		if v.Action != ReportActionSubmit || v.Report == nil || v.Time == v.Report.ReportedAt() {
+			continue
+		}
+		v.Time = v.Report.ReportedAt()
+
+		b, err := json.Marshal(&v)
+		if err != nil {
+			return err
+		}
+
+		batch.Put(append([]byte{}, iter.Key()...), b)
+	}
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	return t.db.Write(batch, nil)
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"encoding/json"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// GraphRisk is the graph-centrality risk of an address: its personalized PageRank seeded from the reported scammer
+// addresses of its chain.
+type GraphRisk struct {
+	Chain   model.Chain `json:"chain"`
+	Address string      `json:"address"`
+
+	// Rank is the address's personalized PageRank, and Score the same rank scaled from 0 to 100.
+	Rank  float64 `json:"rank"`
+	Score float64 `json:"score"`
+
+	// ComputedAt is the unix timestamp of the batch job that computed the rank.
+	ComputedAt int64 `json:"computed_at"`
+}
+
+func graphRiskKey(chain model.Chain, address string) []byte {
+	return []byte("rank_" + string(chain) + "_" + address)
+}
+
+// ReplaceGraphRisks replaces the graph risks of chain with risks.
+func (t *TieDotStore) ReplaceGraphRisks(chain model.Chain, risks []*GraphRisk) error {
+	batch := &leveldb.Batch{}
+
+	iter := t.db.NewIterator(util.BytesPrefix([]byte("rank_"+string(chain)+"_")), nil)
+	for iter.Next() {
+		batch.Delete(append([]byte{}, iter.Key()...))
+	}
+	iter.Release()
+
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	for _, r := range risks {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+
+		batch.Put(graphRiskKey(chain, r.Address), b)
+	}
+
+	return t.db.Write(batch, nil)
+}
+
+// GetGraphRisk returns the graph risk of address on chain, or nil if the batch job didn't reach it.
+func (t *TieDotStore) GetGraphRisk(chain model.Chain, address string) (*GraphRisk, error) {
+	b, err := t.db.Get(graphRiskKey(chain, model.NormalizeAddress(chain, address)), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var r GraphRisk
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
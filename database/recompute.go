@@ -0,0 +1,604 @@
+package database
+
+import (
+	"encoding/json"
+	"github.com/gofrs/uuid"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/perlin-network/safu-go/policy"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRecomputing is returned when taint is recomputed while it is already being recomputed.
+var ErrRecomputing = errors.New("taint is already being recomputed")
+
+// Taint is recomputed into a staging area, from which it replaces the stored taint in a single write once every
+// reported address has been propagated from.
+const (
+	recomputePrefix      = "recompute_"
+	recomputeStateKey    = recomputePrefix + "state"
+	recomputeSourcesKey  = recomputePrefix + "sources"
+	recomputeTaintPrefix = recomputePrefix + "taint_"
+	recomputeScorePrefix = recomputePrefix + "score_"
+	recomputeDirtyPrefix = recomputePrefix + "dirty_"
+)
+
+// RecomputeState is the progress of a recomputation of all taint.
+type RecomputeState struct {
+	ID string `json:"id"`
+
+	// PolicyVersion is the version of the scoring policy taint is recomputed under.
+	PolicyVersion string `json:"policy_version"`
+
+	// Done is the number of reported addresses taint was spread from, out of Total.
+	Done  int `json:"done"`
+	Total int `json:"total"`
+
+	// StartedAt, UpdatedAt and FinishedAt are unix timestamps. FinishedAt is 0 until the results are swapped in.
+	StartedAt  int64 `json:"started_at"`
+	UpdatedAt  int64 `json:"updated_at"`
+	FinishedAt int64 `json:"finished_at"`
+}
+
+// Finished returns whether the recomputation swapped its results in.
+func (s *RecomputeState) Finished() bool {
+	return s.FinishedAt != 0
+}
+
+// RecomputeSource is a reported address taint is spread from.
+type RecomputeSource struct {
+	Chain   model.Chain `json:"chain"`
+	Address string      `json:"address"`
+}
+
+func (s *RecomputeSource) key() string {
+	return string(s.Chain) + "_" + s.Address
+}
+
+// recomputeScoreKey is the key of the staged taint address holds in any asset on chain.
+func recomputeScoreKey(chain model.Chain, address string) []byte {
+	return []byte(recomputeScorePrefix + string(chain) + "_" + address)
+}
+
+// recomputeDirtyKey marks source as spread from again by changes applied while taint is being recomputed.
+func recomputeDirtyKey(source *RecomputeSource) []byte {
+	return []byte(recomputeDirtyPrefix + source.key())
+}
+
+// GetRecomputeState returns the progress of the latest recomputation of taint, or nil if taint was never recomputed.
+func (t *TieDotStore) GetRecomputeState() (*RecomputeState, error) {
+	b, err := t.db.Get([]byte(recomputeStateKey), nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s RecomputeState
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// Recomputing returns whether taint is being recomputed.
+func (t *TieDotStore) Recomputing() bool {
+	return atomic.LoadInt32(&t.recomputing) == 1
+}
+
+// RecomputeTaint wipes all taint and recomputes it from the current reports and graphs under the policy p: taint is
+// spread from every address reported by a report of a status p weighs, and every address keeps the highest taint it
+// gets in each asset. The reports' taint is recomputed too.
+//
+// Taint is recomputed into a staging area, and the stored taint is only replaced once every reported address has
+// been propagated from, in a single write. An interrupted recomputation is resumed where it stopped, unless restart
+// is set or the policy changed since it started. progress is called, if not nil, after every reported address.
+func (t *TieDotStore) RecomputeTaint(p *policy.Policy, restart bool, progress func(s *RecomputeState)) (*RecomputeState, error) {
+	if !atomic.CompareAndSwapInt32(&t.recomputing, 0, 1) {
+		return nil, ErrRecomputing
+	}
+	defer atomic.StoreInt32(&t.recomputing, 0)
+
+	state, err := t.GetRecomputeState()
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []*RecomputeSource
+
+	if state != nil && !state.Finished() && !restart && state.PolicyVersion == p.Version {
+		if sources, err = t.getRecomputeSources(); err != nil {
+			return nil, err
+		}
+	} else {
+		if state, sources, err = t.startRecompute(p); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := t.recomputeFrom(state, sources, p, progress); err != nil {
+		return nil, err
+	}
+
+	// taint isn't written while the results are swapped in, and the addresses reported since the recomputation
+	// started are propagated from first, as are the sources changes were applied to since, which the staged taint
+	// may predate
+	t.taintMu.Lock()
+	defer t.taintMu.Unlock()
+
+	late, err := t.recomputeSources(p)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]struct{})
+	for _, source := range sources {
+		known[source.key()] = struct{}{}
+	}
+
+	for _, source := range late {
+		if _, ok := known[source.key()]; !ok {
+			sources = append(sources, source)
+		}
+	}
+
+	if len(sources) > state.Total {
+		state.Total = len(sources)
+
+		if err := t.putRecomputeSources(&leveldb.Batch{}, state, sources); err != nil {
+			return nil, err
+		}
+
+		if err := t.recomputeFrom(state, sources, p, progress); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := t.recomputeDirty(sources, p); err != nil {
+		return nil, err
+	}
+
+	if err := t.swapRecomputedTaint(state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// startRecompute discards the staging area, and starts a recomputation from the addresses reported now.
+func (t *TieDotStore) startRecompute(p *policy.Policy) (*RecomputeState, []*RecomputeSource, error) {
+	sources, err := t.recomputeSources(p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id, _ := uuid.NewV4()
+	now := time.Now().Unix()
+
+	state := &RecomputeState{
+		ID:            id.String(),
+		PolicyVersion: p.Version,
+		Total:         len(sources),
+		StartedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	// the staged taint of the previous recomputation is discarded as the new sources are stored
+	batch := &leveldb.Batch{}
+	if err := t.deletePrefix(batch, []byte(recomputePrefix)); err != nil {
+		return nil, nil, err
+	}
+
+	if err := t.putRecomputeSources(batch, state, sources); err != nil {
+		return nil, nil, err
+	}
+
+	return state, sources, nil
+}
+
+// putRecomputeSources writes batch, with the sources of the recomputation of state and state added.
+func (t *TieDotStore) putRecomputeSources(batch *leveldb.Batch, state *RecomputeState, sources []*RecomputeSource) error {
+	b, err := json.Marshal(sources)
+	if err != nil {
+		return err
+	}
+	batch.Put([]byte(recomputeSourcesKey), b)
+
+	if b, err = json.Marshal(state); err != nil {
+		return err
+	}
+	batch.Put([]byte(recomputeStateKey), b)
+
+	return t.db.Write(batch, nil)
+}
+
+// recomputeSources returns the addresses reported by a report of a status p weighs, sorted.
+func (t *TieDotStore) recomputeSources(p *policy.Policy) ([]*RecomputeSource, error) {
+	seen := make(map[string]struct{})
+	var sources []*RecomputeSource
+
+	err := t.ForEachReport(func(r *Report) error {
+		if p.ReportStatus[string(r.Status)] <= 0 {
+			return nil
+		}
+
+		source := &RecomputeSource{Chain: r.Chain, Address: model.NormalizeAddress(r.Chain, r.ScammerAddress)}
+		if _, ok := seen[source.key()]; ok {
+			return nil
+		}
+		seen[source.key()] = struct{}{}
+
+		sources = append(sources, source)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(sources, func(i, j int) bool { return sources[i].key() < sources[j].key() })
+
+	return sources, nil
+}
+
+func (t *TieDotStore) getRecomputeSources() ([]*RecomputeSource, error) {
+	b, err := t.db.Get([]byte(recomputeSourcesKey), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []*RecomputeSource
+	if err := json.Unmarshal(b, &sources); err != nil {
+		return nil, err
+	}
+
+	return sources, nil
+}
+
+// recomputeFrom spreads taint from the sources state isn't done with into the staging area. The progress is stored
+// with the staged taint of every source, so that an interrupted recomputation resumes exactly where it stopped.
+func (t *TieDotStore) recomputeFrom(state *RecomputeState, sources []*RecomputeSource, p *policy.Policy, progress func(s *RecomputeState)) error {
+	for state.Done < len(sources) {
+		batch := &leveldb.Batch{}
+
+		if err := t.stage(batch, sources[state.Done], p); err != nil {
+			return err
+		}
+
+		state.Done++
+		state.UpdatedAt = time.Now().Unix()
+
+		b, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+		batch.Put([]byte(recomputeStateKey), b)
+
+		if err := t.db.Write(batch, nil); err != nil {
+			return err
+		}
+
+		if progress != nil {
+			progress(state)
+		}
+	}
+
+	return nil
+}
+
+// recomputeDirty spreads taint again into the staging area from the sources of the recomputation that changes were
+// applied to while it ran, and clears their marks. It must be called with taintMu held.
+func (t *TieDotStore) recomputeDirty(sources []*RecomputeSource, p *policy.Policy) error {
+	for _, source := range sources {
+		key := recomputeDirtyKey(source)
+
+		if _, err := t.db.Get(key, nil); err == leveldb.ErrNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		batch := &leveldb.Batch{}
+
+		if err := t.stage(batch, source, p); err != nil {
+			return err
+		}
+		batch.Delete(key)
+
+		if err := t.db.Write(batch, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// stage adds to batch the writes that merge the taint spread from source into the staging area, every address
+// keeping the highest taint it gets in each asset.
+func (t *TieDotStore) stage(batch *leveldb.Batch, source *RecomputeSource, p *policy.Policy) error {
+	graph, res, _, err := t.propagate(source.Chain, source.Address, 100, p)
+	if err != nil {
+		return errors.Wrapf(err, "failed to spread taint from %s", model.DisplayAddress(source.Chain, source.Address))
+	}
+
+	for _, u := range graph.Addresses() {
+		score, ok := res.Scores[u]
+		if !ok {
+			continue
+		}
+
+		key := recomputeScoreKey(source.Chain, u)
+
+		var staged int
+		if b, err := t.db.Get(key, nil); err == nil {
+			if err := json.Unmarshal(b, &staged); err != nil {
+				return err
+			}
+		} else if err != leveldb.ErrNotFound {
+			return err
+		}
+
+		if score > staged {
+			b, _ := json.Marshal(score)
+			batch.Put(key, b)
+		}
+	}
+
+	for _, a := range res.Assets {
+		key := append([]byte(recomputePrefix), taintKey(source.Chain, a.Address, a.Asset)...)
+		next := &Taint{Chain: source.Chain, Address: a.Address, Asset: a.Asset, Symbol: a.Symbol, Score: a.Score, Since: a.Since}
+
+		if b, err := t.db.Get(key, nil); err == nil {
+			var staged Taint
+			if err := json.Unmarshal(b, &staged); err != nil {
+				return err
+			}
+
+			if !next.outweighs(&staged) {
+				continue
+			}
+		} else if err != leveldb.ErrNotFound {
+			return err
+		}
+
+		b, err := json.Marshal(next)
+		if err != nil {
+			return err
+		}
+		batch.Put(key, b)
+	}
+
+	return nil
+}
+
+// markDirty adds to batch the marks of sources as spread from again, if taint is being recomputed or a recomputation
+// was interrupted, so that the recomputation spreads taint from them again before swapping its results in: it may
+// have spread taint from them before the changes. It must be called with taintMu held.
+func (t *TieDotStore) markDirty(batch *leveldb.Batch, chain model.Chain, sources []string) error {
+	if !t.Recomputing() {
+		state, err := t.GetRecomputeState()
+		if err != nil {
+			return err
+		}
+
+		if state == nil || state.Finished() {
+			return nil
+		}
+	}
+
+	for _, address := range sources {
+		batch.Put(recomputeDirtyKey(&RecomputeSource{Chain: chain, Address: address}), []byte{})
+	}
+
+	return nil
+}
+
+// swapRecomputedTaint replaces the stored taint and the taint of the reports with the staged taint, and clears the
+// staging area, in a single write.
+func (t *TieDotStore) swapRecomputedTaint(state *RecomputeState) error {
+	batch := &leveldb.Batch{}
+
+	if err := t.deletePrefix(batch, []byte("taint_")); err != nil {
+		return err
+	}
+
+	iter := t.db.NewIterator(util.BytesPrefix([]byte(recomputeTaintPrefix)), nil)
+	for iter.Next() {
+		batch.Put(append([]byte{}, iter.Key()[len(recomputePrefix):]...), append([]byte{}, iter.Value()...))
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return err
+	}
+
+	err := t.ForEachReport(func(r *Report) error {
+		key := recomputeScoreKey(r.Chain, model.NormalizeAddress(r.Chain, r.ScammerAddress))
+
+		var score int
+		if b, err := t.db.Get(key, nil); err == nil {
+			if err := json.Unmarshal(b, &score); err != nil {
+				return err
+			}
+		} else if err != leveldb.ErrNotFound {
+			return err
+		}
+
+		if r.Taint == score {
+			return nil
+		}
+		r.Taint = score
+
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+
+		batch.Put([]byte("report_"+r.ID), b)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, prefix := range []string{recomputeTaintPrefix, recomputeScorePrefix, recomputeDirtyPrefix, recomputeSourcesKey} {
+		if err := t.deletePrefix(batch, []byte(prefix)); err != nil {
+			return err
+		}
+	}
+
+	state.FinishedAt = time.Now().Unix()
+	state.UpdatedAt = state.FinishedAt
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	batch.Put([]byte(recomputeStateKey), b)
+
+	return t.db.Write(batch, nil)
+}
+
+// deletePrefix adds the deletion of every key starting with prefix to batch.
+func (t *TieDotStore) deletePrefix(batch *leveldb.Batch, prefix []byte) error {
+	iter := t.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		batch.Delete(append([]byte{}, iter.Key()...))
+	}
+
+	return iter.Error()
+}
+
+// TaintMismatch is a difference between the stored taint and the taint a full recomputation gives.
+type TaintMismatch struct {
+	Chain   model.Chain `json:"chain"`
+	Address string      `json:"address"`
+
+	// Asset is the asset the taint of the address differs in, or empty if the taint of the report ReportID differs.
+	Asset    string `json:"asset,omitempty"`
+	ReportID string `json:"report_id,omitempty"`
+
+	// Stored and Recomputed are the differing taints, and StoredSince and RecomputedSince the unix timestamps the
+	// tainted funds reached the address at.
+	Stored          int   `json:"stored"`
+	Recomputed      int   `json:"recomputed"`
+	StoredSince     int64 `json:"stored_since"`
+	RecomputedSince int64 `json:"recomputed_since"`
+}
+
+// VerifyTaint recomputes all taint under the policy p in memory, without storing it, and returns where the stored
+// taint differs from it. Taint updated by ApplyChanges must not differ from a full recomputation.
+func (t *TieDotStore) VerifyTaint(p *policy.Policy) ([]*TaintMismatch, error) {
+	sources, err := t.recomputeSources(p)
+	if err != nil {
+		return nil, err
+	}
+
+	taints := make(map[string]*Taint)
+	scores := make(map[string]int)
+
+	for _, source := range sources {
+		_, res, _, err := t.propagate(source.Chain, source.Address, 100, p)
+		if err != nil {
+			return nil, err
+		}
+
+		for u, score := range res.Scores {
+			key := string(recomputeScoreKey(source.Chain, u))
+			if score > scores[key] {
+				scores[key] = score
+			}
+		}
+
+		for _, a := range res.Assets {
+			key := string(taintKey(source.Chain, a.Address, a.Asset))
+			next := &Taint{Chain: source.Chain, Address: a.Address, Asset: a.Asset, Symbol: a.Symbol, Score: a.Score, Since: a.Since}
+
+			if current, ok := taints[key]; !ok || next.outweighs(current) {
+				taints[key] = next
+			}
+		}
+	}
+
+	var mismatches []*TaintMismatch
+
+	iter := t.db.NewIterator(util.BytesPrefix([]byte("taint_")), nil)
+	for iter.Next() {
+		var stored Taint
+		if err := json.Unmarshal(iter.Value(), &stored); err != nil {
+			iter.Release()
+			return nil, err
+		}
+
+		m := &TaintMismatch{
+			Chain:       stored.Chain,
+			Address:     stored.Address,
+			Asset:       stored.Asset,
+			Stored:      stored.Score,
+			StoredSince: stored.Since,
+		}
+
+		if recomputed, ok := taints[string(iter.Key())]; ok {
+			delete(taints, string(iter.Key()))
+
+			if recomputed.Score == stored.Score && recomputed.Since == stored.Since {
+				continue
+			}
+
+			m.Recomputed = recomputed.Score
+			m.RecomputedSince = recomputed.Since
+		}
+
+		mismatches = append(mismatches, m)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	// the taint left wasn't stored
+	missing := make([]string, 0, len(taints))
+	for key := range taints {
+		missing = append(missing, key)
+	}
+	sort.Strings(missing)
+
+	for _, key := range missing {
+		recomputed := taints[key]
+
+		mismatches = append(mismatches, &TaintMismatch{
+			Chain:           recomputed.Chain,
+			Address:         recomputed.Address,
+			Asset:           recomputed.Asset,
+			Recomputed:      recomputed.Score,
+			RecomputedSince: recomputed.Since,
+		})
+	}
+
+	err = t.ForEachReport(func(r *Report) error {
+		address := model.NormalizeAddress(r.Chain, r.ScammerAddress)
+
+		score := scores[string(recomputeScoreKey(r.Chain, address))]
+		if r.Taint != score {
+			mismatches = append(mismatches, &TaintMismatch{
+				Chain:      r.Chain,
+				Address:    address,
+				ReportID:   r.ID,
+				Stored:     r.Taint,
+				Recomputed: score,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mismatches, nil
+}
@@ -0,0 +1,299 @@
+package database
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/perlin-network/safu-go/model"
+	"github.com/perlin-network/safu-go/policy"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// newTestStore opens a store in a scratch directory, removed when t ends.
+func newTestStore(t *testing.T) *TieDotStore {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "safu_database")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := NewTieDotStore(dir)
+	t.Cleanup(func() {
+		store.Close()
+		os.RemoveAll(dir)
+	})
+
+	return store
+}
+
+func testAddress(i int) string {
+	return fmt.Sprintf("0x%040x", i)
+}
+
+// testEdge returns a transfer of the native currency from the address from to the address to at the timestamp ts.
+func testEdge(from int, to int, ts int64) *model.Edge {
+	e := model.NewEdge(model.ChainEthereum, testAddress(from), testAddress(to), fmt.Sprintf("0x%x_%x_%x", from, to, ts), model.DirectionForward)
+	e.Timestamp = ts
+	e.Amount = "1"
+	return e
+}
+
+// report reports the address scammer, and spreads its taint.
+func report(t *testing.T, store *TieDotStore, p *policy.Policy, scammer int) *Report {
+	t.Helper()
+
+	id, err := store.AddReport(Report{Chain: model.ChainEthereum, ScammerAddress: testAddress(scammer), VictimAddress: testAddress(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.ApplyChanges(&ChangeSet{Chain: model.ChainEthereum, Sources: []string{testAddress(scammer)}}, p); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := store.GetReport(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return r
+}
+
+// applyEdges stores edges, and spreads taint over them.
+func applyEdges(t *testing.T, store *TieDotStore, p *policy.Policy, edges ...*model.Edge) {
+	t.Helper()
+
+	if err := store.ApplyChanges(&ChangeSet{Chain: model.ChainEthereum, Edges: edges}, p); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// verifyTaint fails t unless the stored taint equals a full recomputation.
+func verifyTaint(t *testing.T, store *TieDotStore, p *policy.Policy) {
+	t.Helper()
+
+	mismatches, err := store.VerifyTaint(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range mismatches {
+		t.Errorf("%s %s report %s: stored %d since %d, recomputed %d since %d", m.Address, m.Asset, m.ReportID, m.Stored, m.StoredSince, m.Recomputed, m.RecomputedSince)
+	}
+}
+
+// storedTaint returns the stored taint by key, and the taint of the reports by reported address and status.
+func storedTaint(t *testing.T, store *TieDotStore) map[string]string {
+	t.Helper()
+
+	taints := make(map[string]string)
+
+	iter := store.db.NewIterator(util.BytesPrefix([]byte("taint_")), nil)
+	for iter.Next() {
+		taints[string(iter.Key())] = string(iter.Value())
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := store.ForEachReport(func(r *Report) error {
+		taints["report_"+r.ScammerAddress+"_"+string(r.Status)] = fmt.Sprint(r.Taint)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return taints
+}
+
+// testHistory reports the addresses 1, 4 and 7, which sent funds down a chain of addresses, and then retracts the
+// report of 4, leaving stale taint that only a recomputation removes.
+func testHistory(t *testing.T, store *TieDotStore, p *policy.Policy) {
+	t.Helper()
+
+	applyEdges(t, store, p, testEdge(1, 2, 10), testEdge(2, 3, 20), testEdge(4, 5, 10), testEdge(5, 6, 20), testEdge(7, 8, 30))
+
+	report(t, store, p, 1)
+	retracted := report(t, store, p, 4)
+	report(t, store, p, 7)
+
+	retracted.Status = ReportRetracted
+	if err := store.UpdateReport(retracted, &ReportVersion{Action: ReportActionRetract, Actor: retracted.AccountID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if mismatches, _ := store.VerifyTaint(p); len(mismatches) == 0 {
+		t.Fatal("retracting a report left no stale taint")
+	}
+}
+
+// interruptedRecompute recomputes taint, and interrupts the recomputation after done sources.
+func interruptedRecompute(t *testing.T, store *TieDotStore, p *policy.Policy, done int) {
+	t.Helper()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("the recomputation wasn't interrupted")
+			}
+		}()
+
+		store.RecomputeTaint(p, false, func(state *RecomputeState) {
+			if state.Done == done {
+				panic("interrupted")
+			}
+		})
+	}()
+
+	state, err := store.GetRecomputeState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Finished() || state.Done != done {
+		t.Fatalf("interrupted recomputation is finished %t, done with %d sources, want unfinished and done with %d", state.Finished(), state.Done, done)
+	}
+	if store.Recomputing() {
+		t.Fatal("Recomputing() = true after the recomputation was interrupted")
+	}
+}
+
+func TestRecomputeTaintResumes(t *testing.T) {
+	store := newTestStore(t)
+	p := policy.Default()
+
+	testHistory(t, store, p)
+	interruptedRecompute(t, store, p, 1)
+
+	// the stored taint isn't replaced until the recomputation finishes
+	if mismatches, _ := store.VerifyTaint(p); len(mismatches) == 0 {
+		t.Fatal("an interrupted recomputation swapped its results in")
+	}
+
+	var resumed []int
+	state, err := store.RecomputeTaint(p, false, func(state *RecomputeState) {
+		resumed = append(resumed, state.Done)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !state.Finished() || state.Done != 2 || state.Total != 2 {
+		t.Errorf("resumed recomputation is finished %t, done with %d of %d sources, want finished and done with 2 of 2", state.Finished(), state.Done, state.Total)
+	}
+	if !reflect.DeepEqual(resumed, []int{2}) {
+		t.Errorf("resumed recomputation reported progress %v, want [2]", resumed)
+	}
+
+	verifyTaint(t, store, p)
+}
+
+func TestRecomputeTaintRestarts(t *testing.T) {
+	store := newTestStore(t)
+	p := policy.Default()
+
+	testHistory(t, store, p)
+	interruptedRecompute(t, store, p, 1)
+
+	var progress []int
+	state, err := store.RecomputeTaint(p, true, func(state *RecomputeState) {
+		progress = append(progress, state.Done)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !state.Finished() || !reflect.DeepEqual(progress, []int{1, 2}) {
+		t.Errorf("restarted recomputation is finished %t with progress %v, want finished with progress [1 2]", state.Finished(), progress)
+	}
+
+	verifyTaint(t, store, p)
+}
+
+func TestRecomputeTaintChangesDuringRecompute(t *testing.T) {
+	store := newTestStore(t)
+	p := policy.Default()
+
+	testHistory(t, store, p)
+
+	applied := false
+	_, err := store.RecomputeTaint(p, false, func(state *RecomputeState) {
+		// progress is reported again with taintMu held for the sources reported meanwhile
+		if applied {
+			return
+		}
+		applied = true
+
+		// the staged taint of 1 predates a new edge from its chain, and 9 is reported once the recomputation started
+		applyEdges(t, store, p, testEdge(3, 10, 30), testEdge(9, 11, 10))
+		report(t, store, p, 9)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifyTaint(t, store, p)
+
+	taints, err := store.GetTaints(model.ChainEthereum, testAddress(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(taints) == 0 {
+		t.Error("the taint of an edge added during the recomputation was lost")
+	}
+}
+
+func TestRecomputeTaintEqualsFromScratch(t *testing.T) {
+	p := policy.Default()
+	late := []*model.Edge{testEdge(3, 10, 30), testEdge(8, 2, 40)}
+
+	// a history recomputed with interruptions and changes in between
+	resumed := newTestStore(t)
+	testHistory(t, resumed, p)
+	interruptedRecompute(t, resumed, p, 1)
+	applyEdges(t, resumed, p, late...)
+
+	if _, err := resumed.RecomputeTaint(p, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same graph and reports, without any taint until it is recomputed once
+	scratch := newTestStore(t)
+
+	graph, err := resumed.LoadChainGraph(model.ChainEthereum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := scratch.InsertGraph(graph); err != nil {
+		t.Fatal(err)
+	}
+
+	err = resumed.ForEachReport(func(r *Report) error {
+		_, err := scratch.AddReport(Report{Chain: r.Chain, ScammerAddress: r.ScammerAddress, Status: r.Status})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := scratch.RecomputeTaint(p, false, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got, want := storedTaint(t, resumed), storedTaint(t, scratch)
+	for key := range want {
+		if got[key] != want[key] {
+			t.Errorf("resumed recomputation stored %s = %s, want %s", key, got[key], want[key])
+		}
+	}
+	for key := range got {
+		if _, ok := want[key]; !ok {
+			t.Errorf("resumed recomputation stored %s = %s, want nothing", key, got[key])
+		}
+	}
+}
@@ -1,13 +1,157 @@
 package database
 
+import (
+	"fmt"
+	"github.com/perlin-network/safu-go/model"
+)
+
+// ReportStatus is the stage of its review a report is at.
+type ReportStatus string
+
+const (
+	// ReportSubmitted is the status of reports that weren't reviewed yet.
+	ReportSubmitted ReportStatus = "submitted"
+
+	// ReportUnderReview, ReportVerified and ReportRejected are the statuses moderators move reports to.
+	ReportUnderReview ReportStatus = "under_review"
+	ReportVerified    ReportStatus = "verified"
+	ReportRejected    ReportStatus = "rejected"
+
+	// ReportDisputed is the status of reports contested until a moderator resolves them.
+	ReportDisputed ReportStatus = "disputed"
+
+	// ReportRetracted is the status of reports their reporter took back.
+	ReportRetracted ReportStatus = "retracted"
+)
+
+// ReportStatuses returns every report status.
+func ReportStatuses() []ReportStatus {
+	return []ReportStatus{ReportSubmitted, ReportUnderReview, ReportVerified, ReportRejected, ReportDisputed, ReportRetracted}
+}
+
+// ParseReportStatus parses a report status.
+func ParseReportStatus(s string) (ReportStatus, error) {
+	for _, status := range ReportStatuses() {
+		if string(status) == s {
+			return status, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown report status %q", s)
+}
+
+// CanModerate returns whether a moderator can move a report of status s to status to: reports can be taken under
+// review, verified or rejected, and reviewed again, but retracted reports are final.
+func (s ReportStatus) CanModerate(to ReportStatus) bool {
+	if s == ReportRetracted || s == to {
+		return false
+	}
+
+	switch to {
+	case ReportUnderReview, ReportVerified, ReportRejected:
+		return true
+	}
+
+	return false
+}
+
 type Report struct {
-	ID             string `json:"id"`
-	ScammerAddress string `json:"scammer_address"`
-	VictimAddress  string `json:"victim_address"`
-	Title          string `json:"title"`
-	Content        string `json:"content"`
-	Proof          string `json:"proof"`
-	Timestamp      int64  `json:"timestamp"`
-	AccountID      string `json:"account_id"`
-	Taint          int    `json:"taint"`
+	ID             string      `json:"id"`
+	Chain          model.Chain `json:"chain"`
+	ScammerAddress string      `json:"scammer_address"`
+	VictimAddress  string      `json:"victim_address"`
+	Title          string      `json:"title"`
+	Content        string      `json:"content"`
+	Proof          string      `json:"proof"`
+	Timestamp      int64       `json:"timestamp"`
+	AccountID      string      `json:"account_id"`
+	Taint          int         `json:"taint"`
+
+	// Direction is the direction the scammer's transactions are crawled in.
+	Direction model.Direction `json:"direction"`
+
+	Status ReportStatus `json:"status"`
+
+	// Version is the number of the latest version of the report, from 1 when it is submitted.
+	Version int `json:"version"`
+
+	// Dispute is set once the holder of the reported address disputes the report.
+	Dispute *Dispute `json:"dispute,omitempty"`
+}
+
+// DisputeStatus is the state of the dispute of a report.
+type DisputeStatus string
+
+const (
+	// DisputeOpen is the status of disputes awaiting a moderator.
+	DisputeOpen DisputeStatus = "open"
+
+	// DisputeUpheld and DisputeDismissed are the statuses of disputes resolved by a moderator rejecting or
+	// verifying the disputed report.
+	DisputeUpheld    DisputeStatus = "upheld"
+	DisputeDismissed DisputeStatus = "dismissed"
+)
+
+// Dispute is the holder of a reported address contesting the report, having proven control of the address.
+type Dispute struct {
+	Status DisputeStatus `json:"status"`
+
+	// Evidence is the counter-evidence attached to the dispute, and Signature the signature of the report's dispute
+	// challenge by the key of the reported address.
+	Evidence  string `json:"evidence"`
+	Signature string `json:"signature"`
+
+	// OpenedAt and ResolvedAt are the unix timestamps the dispute was opened and resolved at.
+	OpenedAt   int64 `json:"opened_at"`
+	ResolvedAt int64 `json:"resolved_at,omitempty"`
+}
+
+// ReportedAt returns the unix timestamp, in seconds, the report was submitted at. Clients send timestamps in
+// seconds, milliseconds, microseconds or nanoseconds, as safu_cli does, and each unit is told from the next by the
+// magnitude of the timestamp: any timestamp in seconds past the year 33658 is taken in a finer unit.
+func (r *Report) ReportedAt() int64 {
+	switch {
+	case r.Timestamp > 1e17:
+		return r.Timestamp / 1e9
+	case r.Timestamp > 1e14:
+		return r.Timestamp / 1e6
+	case r.Timestamp > 1e12:
+		return r.Timestamp / 1e3
+	}
+	return r.Timestamp
+}
+
+// CanDispute returns whether r can be disputed. A report can only be disputed once, while it counts as submitted,
+// under review or verified.
+func (r *Report) CanDispute() bool {
+	if r.Dispute != nil {
+		return false
+	}
+
+	switch r.Status {
+	case ReportSubmitted, ReportUnderReview, ReportVerified:
+		return true
+	}
+
+	return false
+}
+
+// Moderate moves r to the status to at the unix timestamp now. Verifying or rejecting a disputed report resolves its
+// dispute.
+func (r *Report) Moderate(to ReportStatus, now int64) {
+	r.Status = to
+
+	if r.Dispute == nil || r.Dispute.Status != DisputeOpen {
+		return
+	}
+
+	switch to {
+	case ReportVerified:
+		r.Dispute.Status = DisputeDismissed
+	case ReportRejected:
+		r.Dispute.Status = DisputeUpheld
+	default:
+		return
+	}
+	r.Dispute.ResolvedAt = now
 }
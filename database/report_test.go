@@ -0,0 +1,74 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func TestReportedAt(t *testing.T) {
+	at := time.Date(2020, 3, 14, 15, 9, 26, 535897932, time.UTC)
+
+	tests := []struct {
+		name      string
+		timestamp int64
+		want      int64
+	}{
+		{"unset", 0, 0},
+		{"seconds", at.Unix(), at.Unix()},
+		{"milliseconds", at.UnixNano() / 1e6, at.Unix()},
+		{"microseconds", at.UnixNano() / 1e3, at.Unix()},
+		{"nanoseconds", at.UnixNano(), at.Unix()},
+		{"early seconds", 1, 1},
+		{"late seconds", time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC).Unix(), time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC).Unix()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Report{Timestamp: tt.timestamp}
+			if got := r.ReportedAt(); got != tt.want {
+				t.Errorf("ReportedAt() of %d = %d, want %d", tt.timestamp, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrateSubmitTimes(t *testing.T) {
+	store := newTestStore(t)
+
+	at := time.Date(2020, 3, 14, 15, 9, 26, 0, time.UTC)
+
+	id, err := store.AddReport(Report{ScammerAddress: testAddress(1), Timestamp: at.UnixNano()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a submission recorded when nanoseconds were taken for milliseconds
+	versions, err := store.GetReportVersions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := versions[0]
+	v.Time = at.UnixNano() / 1000
+
+	batch := &leveldb.Batch{}
+	if err := putReportVersion(batch, v.Report, v); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.db.Write(batch, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.migrateSubmitTimes(); err != nil {
+		t.Fatal(err)
+	}
+
+	versions, err = store.GetReportVersions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versions[0].Time != at.Unix() {
+		t.Errorf("submission time = %d after migrating, want %d", versions[0].Time, at.Unix())
+	}
+}
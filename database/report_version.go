@@ -0,0 +1,215 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"time"
+)
+
+// ErrReportChanged is returned when a report is changed from a version other than its latest.
+var ErrReportChanged = errors.New("report was changed since the version the change was made to")
+
+// ReportAction is the change a version of a report records.
+type ReportAction string
+
+const (
+	ReportActionSubmit  ReportAction = "submit"
+	ReportActionEdit    ReportAction = "edit"
+	ReportActionRetract ReportAction = "retract"
+
+	// ReportActionModerate records a moderator changing the status of a report. It is also recorded in the audit
+	// trail.
+	ReportActionModerate ReportAction = "moderate"
+
+	// ReportActionDispute records the holder of a reported address disputing a report. It is also recorded in the
+	// audit trail.
+	ReportActionDispute ReportAction = "dispute"
+
+	// ReportActionDelete records the deletion of a report by an admin. The report is gone, but its versions are
+	// kept.
+	ReportActionDelete ReportAction = "delete"
+)
+
+// reportAuditActions are the audit actions recording the report changes that are also recorded in the audit trail.
+var reportAuditActions = map[ReportAction]AuditAction{
+	ReportActionModerate: AuditReportModerate,
+	ReportActionDispute:  AuditReportDispute,
+}
+
+// ReportVersion is a version of a report: the report as a change left it, with who made the change and why.
+type ReportVersion struct {
+	ReportID string       `json:"report_id"`
+	Version  int          `json:"version"`
+	Action   ReportAction `json:"action"`
+
+	// Time is the unix timestamp of the change.
+	Time int64 `json:"time"`
+
+	// Actor is the account of the reporter or moderator, the disputing address, or the admin who made the change,
+	// and Signature their signature of it. Changes made by admins aren't signed.
+	Actor     string `json:"actor"`
+	Signature string `json:"signature,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+
+	Report *Report `json:"report"`
+}
+
+func reportKey(id string) []byte {
+	return []byte("report_" + id)
+}
+
+func reportVersionPrefix(id string) []byte {
+	return []byte("reportversion_" + id + "_")
+}
+
+func reportVersionKey(id string, version int) []byte {
+	return append(reportVersionPrefix(id), fmt.Sprintf("%010d", version)...)
+}
+
+// putReport adds r, and v as its version r.Version, to batch.
+func putReport(batch *leveldb.Batch, r *Report, v *ReportVersion) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	batch.Put(reportKey(r.ID), b)
+
+	return putReportVersion(batch, r, v)
+}
+
+func putReportVersion(batch *leveldb.Batch, r *Report, v *ReportVersion) error {
+	snapshot := *r
+
+	v.ReportID = r.ID
+	v.Version = r.Version
+	v.Report = &snapshot
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	batch.Put(reportVersionKey(r.ID, r.Version), b)
+
+	return nil
+}
+
+// GetReport returns the report id. It returns leveldb.ErrNotFound if there is no such report.
+func (t *TieDotStore) GetReport(id string) (*Report, error) {
+	b, err := t.db.Get(reportKey(id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var r Report
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// UpdateReport stores r, changed from its version r.Version, as its next version, recorded by v. It returns
+// ErrReportChanged if r.Version isn't the latest version of the report, and leveldb.ErrNotFound if the report was
+// deleted.
+func (t *TieDotStore) UpdateReport(r *Report, v *ReportVersion) error {
+	t.reportMu.Lock()
+	defer t.reportMu.Unlock()
+
+	stored, err := t.GetReport(r.ID)
+	if err != nil {
+		return err
+	}
+	if stored.Version != r.Version {
+		return ErrReportChanged
+	}
+
+	r.Version++
+	v.Time = time.Now().Unix()
+
+	batch := &leveldb.Batch{}
+	if err := putReport(batch, r, v); err != nil {
+		return err
+	}
+
+	if action, ok := reportAuditActions[v.Action]; ok {
+		err := putAuditEvent(batch, &AuditEvent{
+			Action:   action,
+			Chain:    r.Chain,
+			Address:  r.ScammerAddress,
+			Actor:    v.Actor,
+			Reason:   v.Reason,
+			ReportID: r.ID,
+			Status:   r.Status,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return t.db.Write(batch, nil)
+}
+
+// DeleteReport deletes the report id, records its deletion as its last version and in the audit trail, and returns
+// the deleted report. It returns leveldb.ErrNotFound if there is no such report.
+func (t *TieDotStore) DeleteReport(id string, actor string, reason string) (*Report, error) {
+	t.reportMu.Lock()
+	defer t.reportMu.Unlock()
+
+	r, err := t.GetReport(id)
+	if err != nil {
+		return nil, err
+	}
+	r.Version++
+
+	batch := &leveldb.Batch{}
+	batch.Delete(reportKey(id))
+
+	err = putReportVersion(batch, r, &ReportVersion{
+		Action: ReportActionDelete,
+		Time:   time.Now().Unix(),
+		Actor:  actor,
+		Reason: reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = putAuditEvent(batch, &AuditEvent{
+		Action:   AuditReportDelete,
+		Chain:    r.Chain,
+		Address:  r.ScammerAddress,
+		Actor:    actor,
+		Reason:   reason,
+		ReportID: id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.db.Write(batch, nil); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetReportVersions returns the versions of the report id, oldest first, including those of a deleted report.
+func (t *TieDotStore) GetReportVersions(id string) ([]*ReportVersion, error) {
+	iter := t.db.NewIterator(util.BytesPrefix(reportVersionPrefix(id)), nil)
+	defer iter.Release()
+
+	versions := []*ReportVersion{}
+	for iter.Next() {
+		var v = &ReportVersion{}
+		if err := json.Unmarshal(iter.Value(), v); err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, v)
+	}
+
+	return versions, iter.Error()
+}
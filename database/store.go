@@ -1,31 +1,30 @@
 package database
 
 import (
-	"container/list"
 	"encoding/json"
-	"fmt"
 	"github.com/gofrs/uuid"
+	"github.com/perlin-network/safu-go/label"
 	"github.com/perlin-network/safu-go/model"
-	"github.com/pkg/errors"
+	"github.com/perlin-network/safu-go/policy"
+	"github.com/perlin-network/safu-go/taint"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/util"
-	"io/ioutil"
 	"log"
-	"net/http"
-	"strings"
-	"time"
+	"sync"
 )
 
-const ES_API_KEY = "4EIR7V4K5QBWDUGJKHFK4BGZ6HWD1NIFT1"
-
 type TieDotStore struct {
 	db *leveldb.DB
-}
 
-type EthTransaction struct {
-	Hash string `json:"hash"`
-	From string `json:"from"`
-	To   string `json:"to"`
+	// taintMu serializes the writes of taint. A recomputation holds it from spreading taint again from the sources
+	// changes were applied to while it ran until its results are swapped in, so that it doesn't lose their taint.
+	taintMu sync.Mutex
+
+	// reportMu serializes the changes of reports, so that every change is made to the latest version of its report.
+	reportMu sync.Mutex
+
+	// recomputing is 1 while taint is being recomputed.
+	recomputing int32
 }
 
 func NewTieDotStore(dir string) *TieDotStore {
@@ -34,33 +33,73 @@ func NewTieDotStore(dir string) *TieDotStore {
 		log.Panicf("open db error: %s", err)
 	}
 
-	return &TieDotStore{
+	t := &TieDotStore{
 		db: db,
 	}
+
+	if err := t.migrateChainKeys(); err != nil {
+		log.Panicf("migrate db error: %s", err)
+	}
+
+	if err := t.migrateClusterEntities(); err != nil {
+		log.Panicf("migrate db error: %s", err)
+	}
+
+	if err := t.migrateReportStatus(); err != nil {
+		log.Panicf("migrate db error: %s", err)
+	}
+
+	if err := t.migrateReportVersions(); err != nil {
+		log.Panicf("migrate db error: %s", err)
+	}
+
+	if err := t.migrateSubmitTimes(); err != nil {
+		log.Panicf("migrate db error: %s", err)
+	}
+
+	return t
+}
+
+// vertexKey is the key of the vertex of address on chain.
+func vertexKey(chain model.Chain, address string) []byte {
+	return []byte("addr_" + string(chain) + "_" + address)
 }
 
 func (t *TieDotStore) AddReport(report Report) (string, error) {
 	id, _ := uuid.NewV4()
 
-	key := "report_" + id.String()
-
 	report.ID = id.String()
+	report.Version = 1
+	if report.Status == "" {
+		report.Status = ReportSubmitted
+	}
+
+	// the submission is the report's first version, signed by its proof
+	batch := &leveldb.Batch{}
 
-	b, err := json.Marshal(report)
+	err := putReport(batch, &report, &ReportVersion{
+		Action:    ReportActionSubmit,
+		Time:      report.ReportedAt(),
+		Actor:     report.AccountID,
+		Signature: report.Proof,
+	})
 	if err != nil {
 		return "", err
 	}
 
-	if err := t.db.Put([]byte(key), b, nil); err != nil {
+	if err := t.db.Write(batch, nil); err != nil {
 		return "", err
 	}
 
 	return id.String(), nil
 }
 
-func (t *TieDotStore) GetReportsByScamAddress(scammerAddress string) ([]*Report, error) {
+// GetReportsByScamAddress returns the reports against scammerAddress on chain.
+func (t *TieDotStore) GetReportsByScamAddress(chain model.Chain, scammerAddress string) ([]*Report, error) {
 	var reports []*Report
 
+	scammerAddress = model.NormalizeAddress(chain, scammerAddress)
+
 	iter := t.db.NewIterator(util.BytesPrefix([]byte("report_")), nil)
 
 	for iter.Next() {
@@ -71,7 +110,7 @@ func (t *TieDotStore) GetReportsByScamAddress(scammerAddress string) ([]*Report,
 			return nil, err
 		}
 
-		if strings.EqualFold(scammerAddress, r.ScammerAddress) {
+		if r.Chain == chain && model.NormalizeAddress(chain, r.ScammerAddress) == scammerAddress {
 			reports = append(reports, r)
 		}
 	}
@@ -80,78 +119,309 @@ func (t *TieDotStore) GetReportsByScamAddress(scammerAddress string) ([]*Report,
 	return reports, nil
 }
 
-func (t *TieDotStore) InsertGraph(graph ...*model.Vertex) error {
+// InsertGraph stores the vertices and edges of graph, merging them into the stored graph of its chain.
+func (t *TieDotStore) InsertGraph(graph *model.Graph) error {
 	batch := &leveldb.Batch{}
-	for _, v := range graph {
 
-		b, err := json.Marshal(v)
+	for _, v := range graph.Vertices() {
+		stored, err := t.getVertex(graph.Chain, v.Address)
+		if err != nil && err != leveldb.ErrNotFound {
+			return err
+		}
+
+		merged := model.NewVertex(graph.Chain, v.Address)
+		for _, u := range []*model.Vertex{stored, v} {
+			if u == nil {
+				continue
+			}
+			for c := range u.Children {
+				merged.Children[c] = struct{}{}
+			}
+			for p := range u.Parents {
+				merged.Parents[p] = struct{}{}
+			}
+		}
+
+		b, err := json.Marshal(merged)
 		if err != nil {
 			return err
 		}
-		key := "addr_" + v.Address
+		batch.Put(vertexKey(graph.Chain, v.Address), b)
+	}
+
+	for _, e := range graph.Edges() {
+		key := []byte("edge_" + e.Key())
 
-		batch.Put([]byte(key), b)
+		merged := e
+		if b, err := t.db.Get(key, nil); err == nil {
+			stored := &model.Edge{}
+			if err := json.Unmarshal(b, stored); err != nil {
+				return err
+			}
+			merged = mergeEdge(stored, e)
+		} else if err != leveldb.ErrNotFound {
+			return err
+		}
+
+		b, err := json.Marshal(merged)
+		if err != nil {
+			return err
+		}
+		batch.Put(key, b)
 	}
 
 	return t.db.Write(batch, nil)
 }
 
-func (t *TieDotStore) GetReportByScamAddress(address string) (*Report, error) {
-	reports, err := t.GetReportsByScamAddress(address)
-	if err != nil {
-		return nil, err
+// mergeEdge merges e into stored, the same transfer stored earlier: the edge keeps the details stored, completed by
+// those of e, and was discovered in the directions either was.
+func mergeEdge(stored *model.Edge, e *model.Edge) *model.Edge {
+	merged := *stored
+
+	if merged.BlockNumber == 0 {
+		merged.BlockNumber = e.BlockNumber
+	}
+	if merged.Timestamp == 0 {
+		merged.Timestamp = e.Timestamp
+	}
+	if merged.Amount == "" {
+		merged.Amount = e.Amount
+	}
+	if merged.Token == nil {
+		merged.Token = e.Token
 	}
 
-	if len(reports) < 1 {
-		return nil, errors.Errorf("could not find report with scam address %s", address)
+	switch {
+	case merged.Direction == "":
+		merged.Direction = e.Direction
+	case e.Direction != "" && e.Direction != merged.Direction:
+		merged.Direction = model.DirectionBoth
 	}
-	return reports[0], nil
+
+	return &merged
 }
 
-func (t *TieDotStore) TaintBFS(address string, taint int) error {
-	address = strings.ToLower(address)
+// GetEdgesFrom returns the edges sent by address on chain.
+func (t *TieDotStore) GetEdgesFrom(chain model.Chain, address string) ([]*model.Edge, error) {
+	var edges []*model.Edge
 
-	err := t.updateReportsTaints(address, taint)
+	iter := t.db.NewIterator(util.BytesPrefix([]byte("edge_"+string(chain)+"_"+address+"_")), nil)
+
+	for iter.Next() {
+		var e = &model.Edge{}
+		if err := json.Unmarshal(iter.Value(), e); err != nil {
+			iter.Release()
+			return nil, err
+		}
+
+		edges = append(edges, e)
+	}
+
+	iter.Release()
+	return edges, iter.Error()
+}
+
+// GetEdgesTo returns the edges received by address on chain.
+func (t *TieDotStore) GetEdgesTo(chain model.Chain, address string) ([]*model.Edge, error) {
+	v, err := t.getVertex(chain, address)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	var edges []*model.Edge
+
+	// edges are keyed by sender, so look them up from the senders recorded on the vertex
+	for p := range v.Parents {
+		sent, err := t.GetEdgesFrom(chain, p)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range sent {
+			if e.To == address {
+				edges = append(edges, e)
+			}
+		}
 	}
 
-	childrenTaint := int(0.3 * float32(taint))
+	return edges, nil
+}
 
-	q := []string{address}
-	visited := make(map[string]struct{})
-	visited[address] = struct{}{}
+// LoadGraph loads the stored graph of the addresses reachable from address on chain within maxDepth hops, following
+// edges in the given direction. A negative maxDepth doesn't bound the graph.
+func (t *TieDotStore) LoadGraph(chain model.Chain, address string, direction model.Direction, maxDepth int) (*model.Graph, error) {
+	graph, _, err := t.loadGraph(chain, []string{address}, direction, maxDepth, false)
+	return graph, err
+}
+
+// LoadEntityGraph is LoadGraph, but also reaches the other addresses of the entity of every address reached, at the
+// same depth. It returns the cluster ID of every clustered address of the graph.
+func (t *TieDotStore) LoadEntityGraph(chain model.Chain, address string, direction model.Direction, maxDepth int) (*model.Graph, map[string]string, error) {
+	return t.loadGraph(chain, []string{address}, direction, maxDepth, true)
+}
+
+// loadGraph loads the stored graph of the addresses reachable from any of addresses, each at the depth of the
+// closest of them, in one traversal.
+func (t *TieDotStore) loadGraph(chain model.Chain, addresses []string, direction model.Direction, maxDepth int, entities bool) (*model.Graph, map[string]string, error) {
+	graph := model.NewGraph(chain)
+
+	depths := make(map[string]int)
+	var q []string
+
+	reach := func(address string, depth int) {
+		if _, ok := depths[address]; !ok {
+			graph.AddVertex(address)
+			depths[address] = depth
+			q = append(q, address)
+		}
+	}
+
+	for _, address := range addresses {
+		reach(model.NormalizeAddress(chain, address), 0)
+	}
 
 	for len(q) != 0 {
 		u := q[0]
 		q = q[1:len(q):len(q)]
 
-		v, err := t.getVertex(u)
-		if err != nil {
-			return err
+		if entities {
+			members, err := t.EntityMembers(chain, u)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			for _, member := range members {
+				reach(member, depths[u])
+			}
 		}
 
-		err = t.updateReportsTaints(v.Address, childrenTaint)
-		if err != nil {
-			return err
+		if maxDepth >= 0 && depths[u] >= maxDepth {
+			continue
 		}
 
-		for p := range v.Children {
-			if _, ok := visited[p]; !ok {
+		var edges []*model.Edge
 
-				q = append(q, p)
-				visited[p] = struct{}{}
+		if direction.Forward() {
+			sent, err := t.GetEdgesFrom(chain, u)
+			if err != nil {
+				return nil, nil, err
 			}
+			edges = append(edges, sent...)
+		}
+
+		if direction.Backward() {
+			received, err := t.GetEdgesTo(chain, u)
+			if err != nil {
+				return nil, nil, err
+			}
+			edges = append(edges, received...)
+		}
+
+		for _, e := range edges {
+			graph.AddEdge(e)
+
+			reach(e.From, depths[u]+1)
+			reach(e.To, depths[u]+1)
 		}
 	}
 
-	return nil
+	if !entities {
+		return graph, nil, nil
+	}
+
+	clusters := make(map[string]string)
+	for _, u := range graph.Addresses() {
+		m, err := t.GetCluster(chain, u)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if m != nil {
+			clusters[u] = m.ClusterID
+		}
+	}
+
+	return graph, clusters, nil
+}
+
+// LoadChainGraph loads the whole stored graph of chain.
+func (t *TieDotStore) LoadChainGraph(chain model.Chain) (*model.Graph, error) {
+	graph := model.NewGraph(chain)
+
+	iter := t.db.NewIterator(util.BytesPrefix([]byte("edge_"+string(chain)+"_")), nil)
+
+	for iter.Next() {
+		var e = &model.Edge{}
+		if err := json.Unmarshal(iter.Value(), e); err != nil {
+			iter.Release()
+			return nil, err
+		}
+
+		graph.AddEdge(e)
+	}
+
+	iter.Release()
+	return graph, iter.Error()
 }
 
-func (t *TieDotStore) getVertex(address string) (*model.Vertex, error) {
-	key := "addr_" + address
+// GetReportsByEntity returns the reports against any address of the entity address belongs to on chain.
+func (t *TieDotStore) GetReportsByEntity(chain model.Chain, address string) ([]*Report, error) {
+	members, err := t.EntityMembers(chain, address)
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []*Report
+	for _, member := range members {
+		r, err := t.GetReportsByScamAddress(chain, member)
+		if err != nil {
+			return nil, err
+		}
+
+		reports = append(reports, r...)
+	}
+
+	return reports, nil
+}
+
+// propagate spreads score from address over the stored graph of chain as configured by the policy p. It returns the
+// graph taint spread over and the caps of its allowlisted addresses, with the result.
+func (t *TieDotStore) propagate(chain model.Chain, address string, score int, p *policy.Policy) (*model.Graph, *taint.Result, map[string]int, error) {
+	graph, entities, err := t.LoadEntityGraph(chain, address, model.DirectionForward, -1)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	barriers := make(map[string]bool)
+	for _, u := range graph.Addresses() {
+		labels, err := t.GetLabels(chain, u)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		if label.StopsTaint(labels, p.Propagation.MinLabelConfidence) {
+			barriers[u] = true
+		}
+	}
+
+	caps, err := t.allowlistCaps(chain, graph.Addresses(), p.Propagation.AllowlistCap)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	opts := p.TaintOptions()
+	opts.Entities = entities
+	opts.Barriers = barriers
+	opts.Caps = caps
+
+	return graph, taint.Propagate(graph, address, score, opts), caps, nil
+}
 
-	b, err := t.db.Get([]byte(key), nil)
+func (t *TieDotStore) getVertex(chain model.Chain, address string) (*model.Vertex, error) {
+	b, err := t.db.Get(vertexKey(chain, address), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -194,29 +464,6 @@ func (t *TieDotStore) GetAllVertices() ([]*model.Vertex, error) {
 	return list, iter.Error()
 }
 
-func (t *TieDotStore) updateReportsTaints(address string, taint int) error {
-	reports, err := t.GetReportsByScamAddress(address)
-	log.Printf("updateReportsTaints len: %d", len(reports))
-	if err != nil {
-		return err
-	}
-
-	batch := &leveldb.Batch{}
-	for _, report := range reports {
-		report.Taint = taint
-
-		b, err := json.Marshal(report)
-		if err != nil {
-			return err
-		}
-
-		log.Printf("update taint ID: %s, taint: %d", report.ID, report.Taint)
-		batch.Put([]byte("report_"+report.ID), b)
-	}
-
-	return t.db.Write(batch, nil)
-}
-
 func (t *TieDotStore) ForEachReport(callback func(report *Report) error) error {
 	prefix := "report_"
 	//prefixLength := len(prefix)
@@ -248,78 +495,6 @@ func (t *TieDotStore) ForEachReport(callback func(report *Report) error) error {
 	return iter.Error()
 }
 
-func (t *TieDotStore) BFSEthAccount(accountID string, apiKey string, callback func(*EthTransaction)) error {
-	accountID = strings.ToLower(accountID)
-
-	type APIResponse struct {
-		Status  string            `json:"status"`
-		Message string            `json:"message"`
-		Result  []*EthTransaction `json:"result"`
-	}
-	ids := list.New()
-	ids.PushFront(accountID)
-	searched := make(map[string]struct{})
-	client := &http.Client{}
-
-	count := 0
-
-	for ids.Len() > 0 {
-		if count == 30 {
-			break
-		}
-		count++
-		time.Sleep(time.Millisecond * 250)
-
-		accountID = ids.Front().Value.(string)
-		ids.Remove(ids.Front())
-
-		if len(searched) >= 256 {
-			return nil
-		}
-		if _, ok := searched[accountID]; ok {
-			continue
-		}
-		searched[accountID] = struct{}{}
-
-		resp, err := client.Get(fmt.Sprintf(
-			"http://api.etherscan.io/api?module=account&action=txlist&address=%s&startblock=0&endblock=99999999&sort=desc&apikey=%s",
-			accountID, apiKey,
-		))
-		if err != nil {
-			log.Printf("unable to retrieve transaction list: %+v\n", err)
-			continue
-		}
-
-		defer resp.Body.Close()
-		_out, err := ioutil.ReadAll(resp.Body)
-		if err != nil {
-			log.Printf("unable to decode output: %+v\n", err)
-			continue
-		}
-
-		var out APIResponse
-		err = json.Unmarshal(_out, &out)
-		if err != nil {
-			log.Printf("unable to unmarshal output: %+v\n", err)
-			continue
-		}
-
-		for _, tx := range out.Result {
-			tx.From = strings.ToLower(tx.From)
-			tx.To = strings.ToLower(tx.To)
-			if tx.To != accountID {
-				continue
-			}
-			log.Println(tx)
-			callback(tx)
-
-			ids.PushBack(tx.From)
-		}
-	}
-
-	return nil
-}
-
 func (t *TieDotStore) Close() {
 	_ = t.db.Close()
 }
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/perlin-network/safu-go/model"
+)
+
+func TestLoadGraphAddresses(t *testing.T) {
+	store := newTestStore(t)
+
+	// two chains funding 5 and 8, which share the upstream of 3
+	graph := model.NewGraph(model.ChainEthereum)
+	for _, e := range []*model.Edge{
+		testEdge(1, 3, 1), testEdge(2, 3, 2), testEdge(3, 4, 3), testEdge(4, 5, 4),
+		testEdge(3, 6, 5), testEdge(6, 7, 6), testEdge(7, 8, 7), testEdge(9, 8, 8),
+		testEdge(5, 10, 9),
+	} {
+		graph.AddEdge(e)
+	}
+	if err := store.InsertGraph(graph); err != nil {
+		t.Fatal(err)
+	}
+
+	want := make(map[string]bool)
+	for _, address := range []int{5, 8} {
+		g, _, err := store.LoadEntityGraph(model.ChainEthereum, testAddress(address), model.DirectionBackward, -1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, u := range g.Addresses() {
+			want[u] = true
+		}
+	}
+
+	g, _, err := store.loadGraph(model.ChainEthereum, []string{testAddress(5), testAddress(8)}, model.DirectionBackward, -1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string]bool)
+	for _, u := range g.Addresses() {
+		got[u] = true
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadGraph() of both addresses reached %v, want the addresses either reaches %v", got, want)
+	}
+}
+
+func TestInsertGraphMergesEdges(t *testing.T) {
+	store := newTestStore(t)
+
+	forward := testEdge(1, 2, 0)
+	forward.Amount = ""
+
+	// the same transfer, found later crawling backward
+	backward := testEdge(1, 2, 5)
+	backward.TxHash = forward.TxHash
+	backward.Direction = model.DirectionBackward
+	backward.BlockNumber = 7
+
+	for _, e := range []*model.Edge{forward, backward} {
+		graph := model.NewGraph(model.ChainEthereum)
+		graph.AddEdge(e)
+
+		if err := store.InsertGraph(graph); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	edges, err := store.GetEdgesFrom(model.ChainEthereum, testAddress(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := *forward
+	want.Direction = model.DirectionBoth
+	want.Timestamp = 5
+	want.BlockNumber = 7
+	want.Amount = "1"
+
+	if len(edges) != 1 || !reflect.DeepEqual(*edges[0], want) {
+		t.Errorf("stored edges = %+v, want %+v", edges, want)
+	}
+}
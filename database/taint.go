@@ -0,0 +1,71 @@
+package database
+
+import (
+	"encoding/json"
+	"github.com/perlin-network/safu-go/model"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Taint is the taint an address holds in a single asset.
+type Taint struct {
+	Chain   model.Chain `json:"chain"`
+	Address string      `json:"address"`
+	Asset   string      `json:"asset"`
+	Symbol  string      `json:"symbol"`
+	Score   int         `json:"score"`
+
+	// Since is the unix timestamp the tainted funds reached the address at, or 0 if it isn't known.
+	Since int64 `json:"since"`
+}
+
+// outweighs returns whether t replaces o when the taint several sources spread to an address is combined: the
+// highest score wins, and of equal scores the one whose tainted funds are known to have arrived first.
+func (t *Taint) outweighs(o *Taint) bool {
+	if t.Score != o.Score {
+		return t.Score > o.Score
+	}
+	return t.Since != 0 && (o.Since == 0 || t.Since < o.Since)
+}
+
+func taintKey(chain model.Chain, address string, asset string) []byte {
+	return []byte("taint_" + string(chain) + "_" + address + "_" + asset)
+}
+
+// getTaint returns the taint stored under key, or nil if there is none.
+func (t *TieDotStore) getTaint(key []byte) (*Taint, error) {
+	b, err := t.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var taint Taint
+	if err := json.Unmarshal(b, &taint); err != nil {
+		return nil, err
+	}
+
+	return &taint, nil
+}
+
+// GetTaints returns the taint address holds in each asset on chain.
+func (t *TieDotStore) GetTaints(chain model.Chain, address string) ([]*Taint, error) {
+	var taints []*Taint
+
+	iter := t.db.NewIterator(util.BytesPrefix([]byte("taint_"+string(chain)+"_"+model.NormalizeAddress(chain, address)+"_")), nil)
+
+	for iter.Next() {
+		var taint = &Taint{}
+		if err := json.Unmarshal(iter.Value(), taint); err != nil {
+			iter.Release()
+			return nil, err
+		}
+
+		taints = append(taints, taint)
+	}
+
+	iter.Release()
+	return taints, iter.Error()
+}
@@ -1,103 +1,322 @@
 package etherscan
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"github.com/nanmu42/etherscan-api"
+	"github.com/perlin-network/safu-go/crawler"
 	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// baseURLs are the Etherscan compatible explorer APIs of the supported chains.
+var baseURLs = map[model.Chain]string{
+	model.ChainEthereum: "https://api.etherscan.io/api",
+	model.ChainBSC:      "https://api.bscscan.com/api",
+	model.ChainPolygon:  "https://api.polygonscan.com/api",
+	model.ChainGoerli:   "https://api-goerli.etherscan.io/api",
+	model.ChainSepolia:  "https://api-sepolia.etherscan.io/api",
+}
+
 // Etherscan client
 type ESClient struct {
-	client *etherscan.Client
+	chain   model.Chain
+	baseURL string
+	apiKey  string
+	client  *http.Client
 }
 
-func NewESClient(APIKey string) *ESClient {
-	return &ESClient{
-		client: etherscan.New(etherscan.Mainnet, APIKey),
+// NewESClient creates a client for the explorer of chain, which must have an Etherscan compatible API.
+func NewESClient(chain model.Chain, APIKey string) (*ESClient, error) {
+	baseURL, ok := baseURLs[chain]
+	if !ok {
+		return nil, errors.Errorf("no Etherscan compatible explorer for chain %s", chain)
 	}
+
+	return &ESClient{
+		chain:   chain,
+		baseURL: baseURL,
+		apiKey:  APIKey,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}, nil
 }
 
-func (e *ESClient) Crawl(address string) ([]*model.Vertex, error) {
-	m, err := e.crawl(address)
+// Chain returns the chain the client reads from.
+func (e *ESClient) Chain() model.Chain {
+	return e.chain
+}
+
+// call queries an account action of the explorer API and decodes its result into out.
+func (e *ESClient) call(action string, params url.Values, out interface{}) error {
+	params.Set("module", "account")
+	params.Set("action", action)
+	params.Set("apikey", e.apiKey)
+
+	resp, err := e.client.Get(e.baseURL + "?" + params.Encode())
 	if err != nil {
-		return nil, err
+		return errors.Wrapf(err, "%s request failed", action)
 	}
+	defer resp.Body.Close()
 
-	var list []*model.Vertex
-	for _, v := range m {
-		list = append(list, v)
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "unable to read %s response", action)
 	}
 
-	return list, nil
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("%s request failed with status %s: %s", action, resp.Status, body)
+	}
+
+	var envelope etherscan.Envelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return errors.Wrapf(err, "malformed %s response", action)
+	}
+
+	if envelope.Status != 1 {
+		return fmt.Errorf("etherscan server: %s", envelope.Message)
+	}
+
+	result := []byte(envelope.Result)
+
+	// some tokens don't report their decimals
+	if action == "tokentx" {
+		result = bytes.Replace(result, []byte(`"tokenDecimal":""`), []byte(`"tokenDecimal":"0"`), -1)
+	}
+
+	return json.Unmarshal(result, out)
 }
 
-func (e *ESClient) crawl(address string) (map[string]*model.Vertex, error) {
-	address = strings.ToLower(address)
+// txListParams are the parameters of a paged transaction list query.
+func txListParams(address string, page int, offset int) url.Values {
+	return url.Values{
+		"address":    {address},
+		"startblock": {"0"},
+		"endblock":   {"99999999"},
+		"page":       {strconv.Itoa(page)},
+		"offset":     {strconv.Itoa(offset)},
+		"sort":       {"asc"},
+	}
+}
 
-	var graph = make(map[string]*model.Vertex)
+// transfersPerAccount bounds the number of transactions of each kind fetched for an account.
+const transfersPerAccount = 1000
 
-	txs, err := e.getTxByAddress(address, 1, 100)
-	if err != nil {
+// Crawl builds the transaction graph around address, following transfers in the given direction.
+func (e *ESClient) Crawl(address string, direction model.Direction) (*model.Graph, error) {
+	return crawler.Crawl(e, e.chain, address, direction)
+}
+
+// Transfers fetches the ether, internal and ERC-20 token transfers sent or received by address.
+func (e *ESClient) Transfers(address string) ([]*model.Edge, error) {
+	var edges []*model.Edge
+
+	txs, err := e.getTxByAddress(address, 1, transfersPerAccount)
+	if err != nil && !noTransactions(err) {
+		return nil, err
+	}
+
+	for _, tx := range txs {
+		// failed transactions don't move any funds
+		if tx.IsError != 0 {
+			continue
+		}
+
+		edge := model.NewEdge(e.chain, tx.From, tx.To, tx.Hash, "")
+		edge.BlockNumber = uint64(tx.BlockNumber)
+		edge.Timestamp = tx.TimeStamp.Time().Unix()
+		edge.Amount = amount(tx.Value)
+
+		edges = append(edges, edge)
+	}
+
+	internalTxs, err := e.getInternalTxByAddress(address, 1, transfersPerAccount)
+	if err != nil && !noTransactions(err) {
 		return nil, err
 	}
 
-	parent := model.NewVertex(address)
-	graph[address] = parent
+	for _, tx := range internalTxs {
+		// reverted and plain contract calls don't move any funds
+		if tx.IsError != 0 || tx.Value == nil || tx.Value.Int().Sign() == 0 {
+			continue
+		}
 
-	visited := make(map[string]bool)
-	visited[address] = true
+		edge := model.NewEdge(e.chain, tx.From, tx.To, tx.Hash, "")
+		edge.BlockNumber = uint64(tx.BlockNumber)
+		edge.Timestamp = tx.TimeStamp.Time().Unix()
+		edge.Amount = amount(tx.Value)
+		edge.Internal = true
+		edge.Position = tx.TraceID
 
-	var q []etherscan.NormalTx
-	q = append(q, txs...)
+		// contracts created internally are reported with an empty recipient
+		if edge.To == "" {
+			edge.To = tx.ContractAddress
+		}
 
-	for len(q) != 0 {
-		u := q[0]
-		q = q[1:len(q):len(q)]
+		edges = append(edges, edge)
+	}
 
-		toTxs, err := e.getTxByAddress(u.To, 1, 100)
-		//log.Printf("q: %d,get: %s, result:= %d", len(q), u.To, len(toTxs))
+	tokenTxs, err := e.getTokenTxByAddress(address, 1, transfersPerAccount)
+	if err != nil && !noTransactions(err) {
+		return nil, err
+	}
 
-		if err != nil {
+	var tokenEdges []*model.Edge
+
+	for _, tx := range tokenTxs {
+		edge := model.NewEdge(e.chain, tx.From, tx.To, tx.Hash, "")
+		edge.BlockNumber = uint64(tx.BlockNumber)
+		edge.Timestamp = tx.TimeStamp.Time().Unix()
+		edge.Amount = amount(tx.Value)
+		edge.Token = &model.Token{
+			Contract: strings.ToLower(tx.ContractAddress),
+			Symbol:   tx.TokenSymbol,
+			Decimals: tx.TokenDecimal,
+		}
+
+		tokenEdges = append(tokenEdges, edge)
+	}
+
+	if err := e.positionTransfers(tokenEdges); err != nil {
+		return nil, err
+	}
+
+	return append(edges, tokenEdges...), nil
+}
+
+// receiptLog is an event log of a transaction receipt.
+type receiptLog struct {
+	Address  string   `json:"address"`
+	Topics   []string `json:"topics"`
+	LogIndex string   `json:"logIndex"`
+}
+
+// positionTransfers sets the position of the token transfers of edges that would otherwise share a key: the
+// explorer doesn't report the log index of token transfers, so it is read from the receipts of the transactions
+// that transfer the same token between the same addresses more than once. edges are in log order.
+func (e *ESClient) positionTransfers(edges []*model.Edge) error {
+	transfers := make(map[string][]*model.Edge)
+	var keys []string
+
+	for _, edge := range edges {
+		key := edge.TransferKey()
+		if _, ok := transfers[key]; !ok {
+			keys = append(keys, key)
+		}
+		transfers[key] = append(transfers[key], edge)
+	}
+
+	receipts := make(map[string][]receiptLog)
+
+	for _, key := range keys {
+		group := transfers[key]
+		if len(group) < 2 {
 			continue
 		}
 
-		for _, toTx := range toTxs {
-			// Convert the to and from address into lower case
-			toTx.To = strings.ToLower(toTx.To)
-			toTx.From = strings.ToLower(toTx.From)
+		first := group[0]
 
-			if toTx.From != u.From {
-				continue
+		logs, ok := receipts[first.TxHash]
+		if !ok {
+			var err error
+			if logs, err = e.receiptLogs(first.TxHash); err != nil {
+				return err
 			}
+			receipts[first.TxHash] = logs
+		}
 
-			if _, ok := visited[toTx.To]; ok {
+		i := 0
+		for _, l := range logs {
+			from, to, ok := crawler.TransferParties(l.Topics)
+			if !ok || i == len(group) || strings.ToLower(l.Address) != first.Asset() ||
+				from != strings.ToLower(first.From) || to != strings.ToLower(first.To) {
 				continue
 			}
 
-			//log.Printf("u from: %s, to: %s", u.From, u.To)
-			//log.Printf("toTx from: %s, to: %s", toTx.From, toTx.To)
+			index, err := strconv.ParseUint(strings.TrimPrefix(l.LogIndex, "0x"), 16, 64)
+			if err != nil {
+				return errors.Wrapf(err, "invalid log index %q", l.LogIndex)
+			}
 
-			v := model.NewVertex(toTx.To)
-			v.Parents[parent.Address] = struct{}{}
-			graph[toTx.To] = v
+			group[i].Position = strconv.FormatUint(index, 10)
+			i++
+		}
+	}
 
-			parent.Children[toTx.To] = struct{}{}
+	return nil
+}
 
-			visited[toTx.To] = true
-			q = append(q, toTx)
-		}
+// receiptLogs fetches the event logs of the receipt of the transaction txHash through the explorer's JSON-RPC proxy.
+func (e *ESClient) receiptLogs(txHash string) ([]receiptLog, error) {
+	params := url.Values{
+		"module": {"proxy"},
+		"action": {"eth_getTransactionReceipt"},
+		"txhash": {txHash},
+		"apikey": {e.apiKey},
+	}
+
+	resp, err := e.client.Get(e.baseURL + "?" + params.Encode())
+	if err != nil {
+		return nil, errors.Wrap(err, "eth_getTransactionReceipt request failed")
+	}
+	defer resp.Body.Close()
+
+	var res struct {
+		Result *struct {
+			Logs []receiptLog `json:"logs"`
+		} `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, errors.Wrap(err, "malformed eth_getTransactionReceipt response")
+	}
+
+	if res.Result == nil {
+		return nil, errors.Errorf("no receipt for transaction %s", txHash)
+	}
+
+	return res.Result.Logs, nil
+}
+
+// amount formats a transferred value, treating missing values as zero.
+func amount(v *etherscan.BigInt) string {
+	if v == nil {
+		return "0"
 	}
+	return v.Int().String()
+}
 
-	return graph, nil
+// noTransactions returns true if err is Etherscan reporting an empty result.
+func noTransactions(err error) bool {
+	return strings.Contains(err.Error(), "No transactions found")
 }
 
 // WIP
 func (e *ESClient) getTxByAddress(address string, page int, offset int) ([]etherscan.NormalTx, error) {
-	startBlock := 0
-	endBlock := 99999999
+	var txs []etherscan.NormalTx
 
 	//log.Printf("getTxByAddress address: %s, page: %d, offset: %d", address, page, offset)
-	return e.client.NormalTxByAddress(address, &startBlock, &endBlock, page, offset, false)
+	err := e.call("txlist", txListParams(address, page, offset), &txs)
+	return txs, err
+}
+
+func (e *ESClient) getInternalTxByAddress(address string, page int, offset int) ([]etherscan.InternalTx, error) {
+	var txs []etherscan.InternalTx
+
+	err := e.call("txlistinternal", txListParams(address, page, offset), &txs)
+	return txs, err
+}
+
+func (e *ESClient) getTokenTxByAddress(address string, page int, offset int) ([]etherscan.ERC20Transfer, error) {
+	var txs []etherscan.ERC20Transfer
+
+	err := e.call("tokentx", txListParams(address, page, offset), &txs)
+	return txs, err
 }
 
 func (e *ESClient) getAllTxsByAddress(address string) ([]etherscan.NormalTx, error) {
@@ -136,7 +355,7 @@ func (e *ESClient) Connected(fromAddr string, toAddr string) (bool, error) {
 		}
 
 		for i := range txs {
-			if strings.EqualFold(txs[i].To,toAddr) {
+			if strings.EqualFold(txs[i].To, toAddr) {
 				return true, nil
 			}
 		}
@@ -0,0 +1,72 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type cytoscapeNode struct {
+	Data struct {
+		ID      string   `json:"id"`
+		Taint   int      `json:"taint"`
+		Reports []string `json:"reports"`
+		Labels  []string `json:"labels"`
+	} `json:"data"`
+}
+
+type cytoscapeEdge struct {
+	Data struct {
+		ID        string `json:"id"`
+		Source    string `json:"source"`
+		Target    string `json:"target"`
+		TxHash    string `json:"tx_hash"`
+		Timestamp int64  `json:"timestamp"`
+		Asset     string `json:"asset"`
+		Symbol    string `json:"symbol"`
+		Amount    string `json:"amount"`
+		Value     string `json:"value"`
+	} `json:"data"`
+}
+
+func writeCytoscape(w io.Writer, g *Graph) error {
+	var doc struct {
+		Data struct {
+			Name string `json:"name"`
+		} `json:"data"`
+		Elements struct {
+			Nodes []*cytoscapeNode `json:"nodes"`
+			Edges []*cytoscapeEdge `json:"edges"`
+		} `json:"elements"`
+	}
+
+	doc.Data.Name = g.Name
+	doc.Elements.Nodes = []*cytoscapeNode{}
+	doc.Elements.Edges = []*cytoscapeEdge{}
+
+	for _, n := range g.Nodes {
+		node := &cytoscapeNode{}
+		node.Data.ID = n.ID
+		node.Data.Taint = n.Taint
+		node.Data.Reports = append([]string{}, n.Reports...)
+		node.Data.Labels = append([]string{}, n.Labels...)
+
+		doc.Elements.Nodes = append(doc.Elements.Nodes, node)
+	}
+
+	for _, e := range g.Edges {
+		edge := &cytoscapeEdge{}
+		edge.Data.ID = e.ID
+		edge.Data.Source = e.Source
+		edge.Data.Target = e.Target
+		edge.Data.TxHash = e.TxHash
+		edge.Data.Timestamp = e.Timestamp
+		edge.Data.Asset = e.Asset
+		edge.Data.Symbol = e.Symbol
+		edge.Data.Amount = e.Amount
+		edge.Data.Value = e.Value
+
+		doc.Elements.Edges = append(doc.Elements.Edges, edge)
+	}
+
+	return json.NewEncoder(w).Encode(&doc)
+}
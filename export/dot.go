@@ -0,0 +1,35 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+func writeDOT(w io.Writer, g *Graph) error {
+	b := bufio.NewWriter(w)
+
+	fmt.Fprintf(b, "digraph %s {\n", dotQuote(g.Name))
+
+	for _, n := range g.Nodes {
+		fmt.Fprintf(b, "\t%s [taint=%d, reports=%s, labels=%s];\n", dotQuote(n.ID), n.Taint,
+			dotQuote(strings.Join(n.Reports, "; ")), dotQuote(strings.Join(n.Labels, "; ")))
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(b, "\t%s -> %s [id=%s, tx_hash=%s, timestamp=%d, asset=%s, symbol=%s, amount=%s, value=%s, label=%s];\n",
+			dotQuote(e.Source), dotQuote(e.Target), dotQuote(e.ID), dotQuote(e.TxHash), e.Timestamp, dotQuote(e.Asset),
+			dotQuote(e.Symbol), dotQuote(e.Amount), dotQuote(e.Value), dotQuote(e.Value+" "+e.Symbol))
+	}
+
+	fmt.Fprintln(b, "}")
+
+	return b.Flush()
+}
@@ -0,0 +1,130 @@
+package export
+
+import (
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Format is a graph file format understood by graph analysis tools.
+type Format string
+
+const (
+	// FormatDOT is the Graphviz DOT language.
+	FormatDOT Format = "dot"
+	// FormatGraphML is the XML format read by Gephi, Cytoscape and yEd.
+	FormatGraphML Format = "graphml"
+	// FormatGEXF is the XML format of Gephi.
+	FormatGEXF Format = "gexf"
+	// FormatCytoscape is the elements JSON format of Cytoscape.js.
+	FormatCytoscape Format = "cytoscape"
+)
+
+var contentTypes = map[Format]string{
+	FormatDOT:       "text/vnd.graphviz",
+	FormatGraphML:   "application/graphml+xml",
+	FormatGEXF:      "application/gexf+xml",
+	FormatCytoscape: "application/json",
+}
+
+// Formats returns every supported format.
+func Formats() []Format {
+	return []Format{FormatDOT, FormatGraphML, FormatGEXF, FormatCytoscape}
+}
+
+// ParseFormat parses the name of a format. The empty string defaults to Cytoscape.js JSON.
+func ParseFormat(s string) (Format, error) {
+	if s == "" {
+		return FormatCytoscape, nil
+	}
+
+	f := Format(strings.ToLower(s))
+	if _, ok := contentTypes[f]; !ok {
+		return "", errors.Errorf("unknown graph format %q", s)
+	}
+
+	return f, nil
+}
+
+// FormatFromAccept returns the first format whose content type is listed in an Accept header, or false if none is.
+// Wildcards don't select any format.
+func FormatFromAccept(accept string) (Format, bool) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		for _, f := range Formats() {
+			if contentTypes[f] == mediaType {
+				return f, true
+			}
+		}
+
+		// some tools still request DOT files by their unregistered type
+		if mediaType == "text/x-graphviz" {
+			return FormatDOT, true
+		}
+	}
+
+	return "", false
+}
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	return contentTypes[f]
+}
+
+// Node is an address of an exported graph.
+type Node struct {
+	ID string
+
+	// Taint is the highest taint the address holds in any asset.
+	Taint int
+
+	// Reports are the titles of the scam reports against the address.
+	Reports []string
+
+	// Labels are the entities the address is known to belong to, as category:name pairs.
+	Labels []string
+}
+
+// Edge is a transfer of an exported graph.
+type Edge struct {
+	ID        string
+	Source    string
+	Target    string
+	TxHash    string
+	Timestamp int64
+	Asset     string
+	Symbol    string
+
+	// Amount is the amount transferred in the asset's base unit, and Value the same amount in whole units.
+	Amount string
+	Value  string
+}
+
+// Graph is a transaction graph annotated for export.
+type Graph struct {
+	Name  string
+	Nodes []*Node
+	Edges []*Edge
+}
+
+// Write serializes g to w in format f.
+func Write(w io.Writer, f Format, g *Graph) error {
+	switch f {
+	case FormatDOT:
+		return writeDOT(w, g)
+	case FormatGraphML:
+		return writeGraphML(w, g)
+	case FormatGEXF:
+		return writeGEXF(w, g)
+	case FormatCytoscape:
+		return writeCytoscape(w, g)
+	}
+
+	return errors.Errorf("unknown graph format %q", f)
+}
@@ -0,0 +1,112 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type gexfNode struct {
+	ID        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexfEdge struct {
+	ID        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues []gexfAttValue `xml:"attvalues>attvalue"`
+}
+
+type gexf struct {
+	XMLName xml.Name `xml:"gexf"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	Version string   `xml:"version,attr"`
+	Graph   struct {
+		DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+		Mode            string           `xml:"mode,attr"`
+		Attributes      []gexfAttributes `xml:"attributes"`
+		Nodes           []gexfNode       `xml:"nodes>node"`
+		Edges           []gexfEdge       `xml:"edges>edge"`
+	} `xml:"graph"`
+}
+
+func writeGEXF(w io.Writer, g *Graph) error {
+	doc := gexf{
+		XMLNS:   "http://gexf.net/1.3",
+		Version: "1.3",
+	}
+	doc.Graph.DefaultEdgeType = "directed"
+	doc.Graph.Mode = "static"
+	doc.Graph.Attributes = []gexfAttributes{
+		{
+			Class: "node",
+			Attributes: []gexfAttribute{
+				{ID: "taint", Title: "taint", Type: "integer"},
+				{ID: "reports", Title: "reports", Type: "string"},
+				{ID: "labels", Title: "labels", Type: "string"},
+			},
+		},
+		{
+			Class: "edge",
+			Attributes: []gexfAttribute{
+				{ID: "tx_hash", Title: "tx_hash", Type: "string"},
+				{ID: "timestamp", Title: "timestamp", Type: "long"},
+				{ID: "asset", Title: "asset", Type: "string"},
+				{ID: "symbol", Title: "symbol", Type: "string"},
+				{ID: "amount", Title: "amount", Type: "string"},
+				{ID: "value", Title: "value", Type: "double"},
+			},
+		},
+	}
+
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfNode{
+			ID:    n.ID,
+			Label: n.ID,
+			AttValues: []gexfAttValue{
+				{For: "taint", Value: strconv.Itoa(n.Taint)},
+				{For: "reports", Value: strings.Join(n.Reports, "; ")},
+				{For: "labels", Value: strings.Join(n.Labels, "; ")},
+			},
+		})
+	}
+
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfEdge{
+			ID:     e.ID,
+			Source: e.Source,
+			Target: e.Target,
+			Label:  e.Value + " " + e.Symbol,
+			AttValues: []gexfAttValue{
+				{For: "tx_hash", Value: e.TxHash},
+				{For: "timestamp", Value: strconv.FormatInt(e.Timestamp, 10)},
+				{For: "asset", Value: e.Asset},
+				{For: "symbol", Value: e.Symbol},
+				{For: "amount", Value: e.Amount},
+				{For: "value", Value: e.Value},
+			},
+		})
+	}
+
+	return writeXML(w, doc)
+}
@@ -0,0 +1,108 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+func writeGraphML(w io.Writer, g *Graph) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "taint", For: "node", AttrName: "taint", AttrType: "int"},
+			{ID: "reports", For: "node", AttrName: "reports", AttrType: "string"},
+			{ID: "labels", For: "node", AttrName: "labels", AttrType: "string"},
+			{ID: "tx_hash", For: "edge", AttrName: "tx_hash", AttrType: "string"},
+			{ID: "timestamp", For: "edge", AttrName: "timestamp", AttrType: "long"},
+			{ID: "asset", For: "edge", AttrName: "asset", AttrType: "string"},
+			{ID: "symbol", For: "edge", AttrName: "symbol", AttrType: "string"},
+			{ID: "amount", For: "edge", AttrName: "amount", AttrType: "string"},
+			{ID: "value", For: "edge", AttrName: "value", AttrType: "double"},
+		},
+	}
+	doc.Graph.ID = g.Name
+	doc.Graph.EdgeDefault = "directed"
+
+	for _, n := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: n.ID,
+			Data: []graphMLData{
+				{Key: "taint", Value: strconv.Itoa(n.Taint)},
+				{Key: "reports", Value: strings.Join(n.Reports, "; ")},
+				{Key: "labels", Value: strings.Join(n.Labels, "; ")},
+			},
+		})
+	}
+
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			ID:     e.ID,
+			Source: e.Source,
+			Target: e.Target,
+			Data: []graphMLData{
+				{Key: "tx_hash", Value: e.TxHash},
+				{Key: "timestamp", Value: strconv.FormatInt(e.Timestamp, 10)},
+				{Key: "asset", Value: e.Asset},
+				{Key: "symbol", Value: e.Symbol},
+				{Key: "amount", Value: e.Amount},
+				{Key: "value", Value: e.Value},
+			},
+		})
+	}
+
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, doc interface{}) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -1,8 +1,9 @@
 module github.com/perlin-network/safu-go
 
 require (
-	github.com/BurntSushi/toml v0.3.1 // indirect
+	github.com/BurntSushi/toml v0.3.1
 	github.com/HouzuoGuo/tiedot v0.0.0-20190118065647-a9d98e48e5ad
+	github.com/decred/dcrd/dcrec/secp256k1/v4 v4.0.1
 	github.com/dgrijalva/jwt-go v3.2.0+incompatible // indirect
 	github.com/go-playground/locales v0.12.1 // indirect
 	github.com/go-playground/universal-translator v0.16.0 // indirect
@@ -15,9 +16,9 @@ require (
 	github.com/rs/cors v1.6.0
 	github.com/rs/zerolog v1.11.0
 	github.com/syndtr/goleveldb v0.0.0-20181128100959-b001fa50d6b2
-	golang.org/x/crypto v0.0.0-20190103213133-ff983b9c42bc // indirect
+	golang.org/x/crypto v0.0.0-20190103213133-ff983b9c42bc
 	golang.org/x/sys v0.0.0-20190116161447-11f53e031339 // indirect
 	gopkg.in/go-playground/validator.v9 v9.25.0
 	gopkg.in/urfave/cli.v1 v1.20.0
-	gopkg.in/yaml.v2 v2.2.2 // indirect
+	gopkg.in/yaml.v2 v2.2.2
 )
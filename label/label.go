@@ -0,0 +1,107 @@
+package label
+
+import (
+	"strings"
+
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+)
+
+// Category is the kind of entity a labeled address belongs to.
+type Category string
+
+const (
+	CategoryExchange   Category = "exchange"
+	CategoryMixer      Category = "mixer"
+	CategoryBridge     Category = "bridge"
+	CategoryDeFi       Category = "defi"
+	CategorySanctioned Category = "sanctioned"
+	CategoryCustom     Category = "custom"
+)
+
+// MinConfidence is the default confidence below which a label doesn't affect taint propagation.
+const MinConfidence = 0.5
+
+// Categories returns every category.
+func Categories() []Category {
+	return []Category{CategoryExchange, CategoryMixer, CategoryBridge, CategoryDeFi, CategorySanctioned, CategoryCustom}
+}
+
+// ParseCategory parses the name of a category.
+func ParseCategory(s string) (Category, error) {
+	c := Category(strings.ToLower(strings.TrimSpace(s)))
+	for _, category := range Categories() {
+		if c == category {
+			return c, nil
+		}
+	}
+
+	return "", errors.Errorf("unknown label category %q", s)
+}
+
+// StopsTaint returns whether taint stops at addresses of the category. Exchanges, bridges and DeFi contracts pool
+// the funds of countless users, so taint reaching them would spread to every user they pay out to.
+func (c Category) StopsTaint() bool {
+	return c == CategoryExchange || c == CategoryBridge || c == CategoryDeFi
+}
+
+// Label tags an address with the entity it is known to belong to.
+type Label struct {
+	Chain    model.Chain `json:"chain"`
+	Address  string      `json:"address"`
+	Category Category    `json:"category"`
+
+	// Name is the name of the entity, e.g. the exchange's.
+	Name string `json:"name"`
+
+	// Source is where the label comes from, e.g. a public dataset or an analyst. An address holds one label per
+	// source.
+	Source string `json:"source"`
+
+	// Confidence is how likely the label is to be right, from 0 to 1.
+	Confidence float64 `json:"confidence"`
+
+	// UpdatedAt is the unix timestamp the label was last stored at.
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// Normalize validates the label, and normalizes its chain and address.
+func (l *Label) Normalize() error {
+	chain, err := model.ParseChain(string(l.Chain))
+	if err != nil {
+		return err
+	}
+	l.Chain = chain
+
+	address, err := model.ParseAddress(chain, l.Address)
+	if err != nil {
+		return err
+	}
+	l.Address = address.Key()
+
+	if l.Category, err = ParseCategory(string(l.Category)); err != nil {
+		return err
+	}
+
+	if l.Source == "" {
+		return errors.New("label has no source")
+	}
+
+	if l.Confidence < 0 || l.Confidence > 1 {
+		return errors.Errorf("label confidence must be between 0 and 1, got %v", l.Confidence)
+	}
+
+	return nil
+}
+
+// StopsTaint returns whether taint stops at an address holding labels, counting the labels with at least
+// minConfidence.
+func StopsTaint(labels []*Label, minConfidence float64) bool {
+	for _, l := range labels {
+		if l.Category.StopsTaint() && l.Confidence >= minConfidence {
+			return true
+		}
+	}
+
+	return false
+}
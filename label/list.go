@@ -0,0 +1,296 @@
+package label
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+)
+
+// ListFormat is the file format of an address list.
+type ListFormat string
+
+const (
+	// ListSDNXML is the XML format of OFAC's Specially Designated Nationals list, sdn.xml.
+	ListSDNXML ListFormat = "sdn_xml"
+
+	// ListSDNCSV is the CSV format of OFAC's Specially Designated Nationals list, sdn.csv: header-less rows whose
+	// remarks list the entity's digital currency addresses.
+	ListSDNCSV ListFormat = "sdn_csv"
+
+	// ListPlain is a plain text list holding an address per line, optionally preceded by its chain. Blank lines and
+	// lines starting with # are ignored.
+	ListPlain ListFormat = "plain"
+)
+
+// ListFormats returns every address list format.
+func ListFormats() []ListFormat {
+	return []ListFormat{ListSDNXML, ListSDNCSV, ListPlain}
+}
+
+// ParseListFormat parses the name of an address list format.
+func ParseListFormat(s string) (ListFormat, error) {
+	f := ListFormat(strings.ToLower(strings.TrimSpace(s)))
+	for _, format := range ListFormats() {
+		if f == format {
+			return f, nil
+		}
+	}
+
+	return "", errors.Errorf("unknown list format %q", s)
+}
+
+// sdnCurrencies maps the currency codes of OFAC's digital currency addresses to the chains they are on. Addresses
+// of tokens are on the chain the token is issued on; those issued on several chains are only supported when their
+// address is valid on the chain listed here.
+var sdnCurrencies = map[string]model.Chain{
+	"XBT":   model.ChainBitcoin,
+	"ETH":   model.ChainEthereum,
+	"USDT":  model.ChainEthereum,
+	"USDC":  model.ChainEthereum,
+	"DAI":   model.ChainEthereum,
+	"BSC":   model.ChainBSC,
+	"MATIC": model.ChainPolygon,
+}
+
+var sdnAddressPattern = regexp.MustCompile(`Digital Currency Address - ([A-Za-z0-9]+)[\s:]+([A-Za-z0-9]+)`)
+
+// ListOptions tune how the labels of an address list are made.
+type ListOptions struct {
+	// Source is the source of the labels, e.g. ofac.
+	Source string
+
+	// Category is the category of the labels, CategorySanctioned by default.
+	Category Category
+
+	// Chain is the chain of the addresses of plain lists that don't name one, Ethereum by default.
+	Chain model.Chain
+
+	// Name is the name of the labels of plain lists. SDN labels are named after the listed entity.
+	Name string
+}
+
+// List is the labels read from an address list.
+type List struct {
+	Labels []*Label
+
+	// Skipped holds the addresses of the list that weren't labeled: malformed addresses, and addresses on chains
+	// that aren't supported.
+	Skipped []string
+}
+
+// ParseList reads the addresses of a sanctions list or blocklist in format. An address listed more than once is
+// labeled once, after its first entry.
+func ParseList(r io.Reader, format ListFormat, opts ListOptions) (*List, error) {
+	if opts.Source == "" {
+		return nil, errors.New("list has no source")
+	}
+	if opts.Category == "" {
+		opts.Category = CategorySanctioned
+	}
+	if opts.Chain == "" {
+		opts.Chain = model.ChainEthereum
+	}
+
+	l := &list{opts: opts, List: &List{}, seen: make(map[string]struct{})}
+
+	var err error
+	switch format {
+	case ListSDNXML:
+		err = l.readSDNXML(r)
+	case ListSDNCSV:
+		err = l.readSDNCSV(r)
+	case ListPlain:
+		err = l.readPlain(r)
+	default:
+		err = errors.Errorf("unknown list format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return l.List, nil
+}
+
+type list struct {
+	*List
+	opts ListOptions
+	seen map[string]struct{}
+}
+
+func (l *list) add(chain model.Chain, address string, name string) {
+	label := &Label{
+		Chain:      chain,
+		Address:    address,
+		Category:   l.opts.Category,
+		Name:       name,
+		Source:     l.opts.Source,
+		Confidence: 1,
+	}
+
+	// sanctions lists don't always carry valid EIP-55 checksums, and the authority's word settles the address
+	if !chain.UTXO() {
+		label.Address = strings.ToLower(label.Address)
+	}
+
+	if err := label.Normalize(); err != nil {
+		l.Skipped = append(l.Skipped, address)
+		return
+	}
+
+	key := string(label.Chain) + "_" + label.Address
+	if _, ok := l.seen[key]; ok {
+		return
+	}
+	l.seen[key] = struct{}{}
+
+	l.Labels = append(l.Labels, label)
+}
+
+// addSDN labels an OFAC digital currency address, listed with the code of its currency.
+func (l *list) addSDN(currency string, address string, name string) {
+	chain, ok := sdnCurrencies[strings.ToUpper(currency)]
+	if !ok {
+		l.Skipped = append(l.Skipped, address)
+		return
+	}
+
+	l.add(chain, address, name)
+}
+
+type sdnEntry struct {
+	FirstName string   `xml:"firstName"`
+	LastName  string   `xml:"lastName"`
+	Programs  []string `xml:"programList>program"`
+	IDs       []struct {
+		Type   string `xml:"idType"`
+		Number string `xml:"idNumber"`
+	} `xml:"idList>id"`
+}
+
+func (l *list) readSDNXML(r io.Reader) error {
+	decoder := xml.NewDecoder(r)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "malformed SDN XML")
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "sdnEntry" {
+			continue
+		}
+
+		var entry sdnEntry
+		if err := decoder.DecodeElement(&entry, &start); err != nil {
+			return errors.Wrap(err, "malformed SDN entry")
+		}
+
+		name := strings.TrimSpace(entry.FirstName + " " + entry.LastName)
+		if len(entry.Programs) > 0 {
+			name += " [" + strings.Join(entry.Programs, "] [") + "]"
+		}
+
+		for _, id := range entry.IDs {
+			if !strings.HasPrefix(id.Type, "Digital Currency Address - ") {
+				continue
+			}
+
+			l.addSDN(strings.TrimPrefix(id.Type, "Digital Currency Address - "), strings.TrimSpace(id.Number), name)
+		}
+	}
+}
+
+func (l *list) readSDNCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "unable to read SDN CSV line %d", line)
+		}
+
+		// rows are numbered, named, typed and assigned to programs; nulls are spelled -0-
+		if len(record) < 2 {
+			continue
+		}
+
+		name := sdnField(record[1])
+		if len(record) > 3 {
+			if program := sdnField(record[3]); program != "" {
+				name += " [" + program + "]"
+			}
+		}
+
+		for _, field := range record {
+			for _, match := range sdnAddressPattern.FindAllStringSubmatch(field, -1) {
+				l.addSDN(match[1], match[2], name)
+			}
+		}
+	}
+}
+
+func sdnField(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "-0-" {
+		return ""
+	}
+	return s
+}
+
+func (l *list) readPlain(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.FieldsFunc(text, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})
+
+		switch len(fields) {
+		case 1:
+			l.add(l.opts.Chain, fields[0], l.opts.Name)
+		case 2:
+			chain, err := model.ParseChain(fields[0])
+			if err != nil {
+				return errors.Wrapf(err, "invalid chain on line %d", line)
+			}
+			l.add(chain, fields[1], l.opts.Name)
+		default:
+			return errors.Errorf("expected [CHAIN] ADDRESS on line %d, got %q", line, text)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// Sanctioned returns the sources of the sanctioned labels among labels. A sanctioned label is a hard flag: it counts
+// whatever its confidence.
+func Sanctioned(labels []*Label) []string {
+	var sources []string
+	for _, l := range labels {
+		if l.Category == CategorySanctioned {
+			sources = append(sources, l.Source)
+		}
+	}
+
+	return sources
+}
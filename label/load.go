@@ -0,0 +1,119 @@
+package label
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/perlin-network/safu-go/model"
+	"github.com/pkg/errors"
+)
+
+// ParseCSV reads labels from a CSV file with a header row naming its columns: chain, address, category, name,
+// source and confidence, of which only address and category are required. Addresses default to Ethereum, labels without a source are attributed to source, and labels without a confidence are
+// given a confidence of 1.
+func ParseCSV(r io.Reader, source string) ([]*Label, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read the CSV header")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range []string{"address", "category"} {
+		if _, ok := columns[name]; !ok {
+			return nil, errors.Errorf("CSV has no %s column", name)
+		}
+	}
+
+	var labels []*Label
+
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read CSV line %d", line)
+		}
+
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		l := &Label{
+			Address:    field("address"),
+			Category:   Category(field("category")),
+			Name:       field("name"),
+			Source:     field("source"),
+			Confidence: 1,
+		}
+
+		if chain := field("chain"); chain != "" {
+			l.Chain = model.Chain(chain)
+		}
+
+		if confidence := field("confidence"); confidence != "" {
+			if l.Confidence, err = strconv.ParseFloat(confidence, 64); err != nil {
+				return nil, errors.Wrapf(err, "invalid confidence on CSV line %d", line)
+			}
+		}
+
+		if err := finish(l, source); err != nil {
+			return nil, errors.Wrapf(err, "invalid label on CSV line %d", line)
+		}
+
+		labels = append(labels, l)
+	}
+
+	return labels, nil
+}
+
+// ParseJSON reads labels from a JSON array of labels. Labels without a source are attributed to source, and labels
+// without a confidence are given a confidence of 1.
+func ParseJSON(r io.Reader, source string) ([]*Label, error) {
+	var raw []struct {
+		Label
+		Confidence *float64 `json:"confidence"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, errors.Wrap(err, "malformed labels")
+	}
+
+	labels := make([]*Label, 0, len(raw))
+	for i, r := range raw {
+		l := r.Label
+		l.Confidence = 1
+		if r.Confidence != nil {
+			l.Confidence = *r.Confidence
+		}
+
+		if err := finish(&l, source); err != nil {
+			return nil, errors.Wrapf(err, "invalid label %d", i)
+		}
+
+		labels = append(labels, &l)
+	}
+
+	return labels, nil
+}
+
+func finish(l *Label, source string) error {
+	if l.Source == "" {
+		l.Source = source
+	}
+
+	return l.Normalize()
+}
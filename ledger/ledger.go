@@ -26,24 +26,34 @@ type Account struct {
 func (l *Ledger) GetReps(accounts []string) (int, error) {
 	var total int
 	for _, acct := range accounts {
-		acctInfo, err := l.callLedgerGetAccount(acct)
+		repVal, err := l.GetRep(acct)
 		if err != nil {
 			return 0, err
 		}
-		repVal := 0
-		for _, rep := range acctInfo.ReputationReceived {
-			if rep.Effect == "Positive" {
-				repVal++
-			}
-			if rep.Effect == "Negative" {
-				repVal--
-			}
-		}
 		total += repVal
 	}
 	return total, nil
 }
 
+// GetRep returns the reputation of an account: the number of positive reputation effects it received, less the
+// number of negative ones.
+func (l *Ledger) GetRep(account string) (int, error) {
+	acctInfo, err := l.callLedgerGetAccount(account)
+	if err != nil {
+		return 0, err
+	}
+	repVal := 0
+	for _, rep := range acctInfo.ReputationReceived {
+		if rep.Effect == "Positive" {
+			repVal++
+		}
+		if rep.Effect == "Negative" {
+			repVal--
+		}
+	}
+	return repVal, nil
+}
+
 func (l *Ledger) callLedgerGetAccount(accountID string) (*Account, error) {
 	payload := fmt.Sprintf(`{
 		"account_id": "%s"
@@ -0,0 +1,280 @@
+package model
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	// ErrZeroAddress occurs when an address is the zero address, which nobody controls.
+	ErrZeroAddress = errors.New("address is the zero address")
+)
+
+// Address is an address validated for a chain.
+type Address struct {
+	Chain Chain
+
+	// key is the normalized form of the address, used to store and compare it.
+	key string
+}
+
+// ParseAddress validates s as an address of chain. Hex addresses must be 20 bytes long and, when mixed case, carry
+// a valid EIP-55 checksum. Bitcoin addresses must be valid Base58Check P2PKH/P2SH or Bech32 segwit addresses.
+func ParseAddress(chain Chain, s string) (Address, error) {
+	s = strings.TrimSpace(s)
+
+	var err error
+	if chain.UTXO() {
+		err = validateBitcoinAddress(s)
+	} else {
+		err = validateHexAddress(s)
+	}
+
+	if err != nil {
+		return Address{}, errors.Wrapf(err, "invalid %s address %q", chain, s)
+	}
+
+	return Address{Chain: chain, key: NormalizeAddress(chain, s)}, nil
+}
+
+// NormalizeAddress returns the normalized form of an address, without validating it: hex and Bech32 addresses are
+// lower-cased, whereas Base58 addresses are case sensitive and returned as is.
+func NormalizeAddress(chain Chain, s string) string {
+	s = strings.TrimSpace(s)
+
+	if chain.UTXO() && !strings.HasPrefix(strings.ToLower(s), bech32HRP+"1") {
+		return s
+	}
+	return strings.ToLower(s)
+}
+
+// Key returns the normalized form of the address.
+func (a Address) Key() string {
+	return a.key
+}
+
+// String returns the address for display, with an EIP-55 checksum for hex addresses.
+func (a Address) String() string {
+	return DisplayAddress(a.Chain, a.key)
+}
+
+// DisplayAddress formats a stored address for display, with an EIP-55 checksum for hex addresses.
+func DisplayAddress(chain Chain, address string) string {
+	if chain.UTXO() {
+		return address
+	}
+	return ChecksumAddress(address)
+}
+
+// ChecksumAddress returns the EIP-55 mixed case checksum encoding of a hex address. Malformed addresses are
+// returned as is.
+func ChecksumAddress(address string) string {
+	lower := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(address, "0x"), "0X"))
+	if len(lower) != 40 {
+		return address
+	}
+
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(lower))
+	hash := h.Sum(nil)
+
+	out := []byte(lower)
+	for i, c := range out {
+		nibble := hash[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+
+		if c >= 'a' && c <= 'f' && nibble&0xf >= 8 {
+			out[i] = c - 'a' + 'A'
+		}
+	}
+
+	return "0x" + string(out)
+}
+
+func validateHexAddress(s string) error {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return errors.New("address must start with 0x")
+	}
+
+	body := s[2:]
+	if len(body) != 40 {
+		return errors.Errorf("address must be 20 bytes long, got %d hex characters", len(body))
+	}
+
+	b, err := hex.DecodeString(body)
+	if err != nil {
+		return errors.New("address must be hex encoded")
+	}
+
+	if bytes.Equal(b, make([]byte, 20)) {
+		return ErrZeroAddress
+	}
+
+	// all lower or all upper case addresses carry no checksum
+	if body != strings.ToLower(body) && body != strings.ToUpper(body) {
+		if ChecksumAddress(s) != "0x"+body {
+			return errors.New("address has an invalid EIP-55 checksum")
+		}
+	}
+
+	return nil
+}
+
+const (
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	// version bytes of mainnet P2PKH and P2SH addresses
+	p2pkhVersion = 0x00
+	p2shVersion  = 0x05
+
+	bech32HRP     = "bc"
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	// checksum constants of Bech32 (witness v0) and Bech32m (witness v1+)
+	bech32Const  = 1
+	bech32mConst = 0x2bc830a3
+)
+
+func validateBitcoinAddress(s string) error {
+	if strings.HasPrefix(strings.ToLower(s), bech32HRP+"1") {
+		return validateBech32Address(s)
+	}
+	return validateBase58Address(s)
+}
+
+func validateBase58Address(s string) error {
+	n := new(big.Int)
+	for _, c := range s {
+		i := strings.IndexRune(base58Alphabet, c)
+		if i < 0 {
+			return errors.Errorf("invalid base58 character %q", c)
+		}
+		n.Mul(n, big.NewInt(58))
+		n.Add(n, big.NewInt(int64(i)))
+	}
+
+	// every leading 1 encodes a leading zero byte
+	zeros := len(s) - len(strings.TrimLeft(s, "1"))
+	b := append(make([]byte, zeros), n.Bytes()...)
+
+	if len(b) != 25 {
+		return errors.New("address must decode to 25 bytes")
+	}
+
+	first := sha256.Sum256(b[:21])
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], b[21:]) {
+		return errors.New("address has an invalid checksum")
+	}
+
+	if b[0] != p2pkhVersion && b[0] != p2shVersion {
+		return errors.Errorf("unsupported address version %d", b[0])
+	}
+
+	if bytes.Equal(b[1:21], make([]byte, 20)) {
+		return ErrZeroAddress
+	}
+
+	return nil
+}
+
+func bech32Polymod(values []byte) uint32 {
+	gen := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := uint(0); i < 5; i++ {
+			if (top>>i)&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+
+	return chk
+}
+
+func validateBech32Address(s string) error {
+	if s != strings.ToLower(s) && s != strings.ToUpper(s) {
+		return errors.New("address must not be mixed case")
+	}
+	s = strings.ToLower(s)
+
+	sep := strings.LastIndexByte(s, '1')
+	if len(s) > 90 || len(s)-sep-1 < 7 {
+		return errors.New("address has an invalid length")
+	}
+
+	// testnet and other networks' addresses have checksums as valid as mainnet ones
+	if s[:sep] != bech32HRP {
+		return errors.Errorf("address is for network %q, not %q", s[:sep], bech32HRP)
+	}
+
+	var values []byte
+	for _, c := range s[:sep] {
+		values = append(values, byte(c>>5))
+	}
+	values = append(values, 0)
+	for _, c := range s[:sep] {
+		values = append(values, byte(c&31))
+	}
+
+	var data []byte
+	for _, c := range s[sep+1:] {
+		i := strings.IndexRune(bech32Charset, c)
+		if i < 0 {
+			return errors.Errorf("invalid bech32 character %q", c)
+		}
+		data = append(data, byte(i))
+	}
+
+	witnessVersion := data[0]
+
+	expected := uint32(bech32Const)
+	if witnessVersion > 0 {
+		expected = bech32mConst
+	}
+
+	if bech32Polymod(append(values, data...)) != expected {
+		return errors.New("address has an invalid checksum")
+	}
+
+	// regroup the 5-bit groups of the witness program into bytes
+	var program []byte
+	var acc, bits uint
+	for _, v := range data[1 : len(data)-6] {
+		acc = acc<<5 | uint(v)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			program = append(program, byte(acc>>bits))
+		}
+	}
+
+	if bits >= 5 || acc&(1<<bits-1) != 0 {
+		return errors.New("address has invalid padding")
+	}
+
+	if witnessVersion > 16 || len(program) < 2 || len(program) > 40 {
+		return errors.New("address has an invalid witness program")
+	}
+
+	if witnessVersion == 0 && len(program) != 20 && len(program) != 32 {
+		return errors.New("address has an invalid witness v0 program length")
+	}
+
+	if bytes.Equal(program, make([]byte, len(program))) {
+		return ErrZeroAddress
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		name  string
+		chain Chain
+		in    string
+		key   string
+		valid bool
+	}{
+		// EIP-55 test vectors
+		{"checksummed", ChainEthereum, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", true},
+		{"checksummed", ChainEthereum, "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359", "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359", true},
+		{"checksummed", ChainBSC, "0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB", "0xdbf03b407c01e7cd3cbea99509d93f8dddc8c6fb", true},
+		{"checksummed", ChainPolygon, "0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb", "0xd1220a0cf47c7b9be7a2e6ba89f429762e7b9adb", true},
+		{"lower case", ChainEthereum, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", true},
+		{"upper case", ChainEthereum, "0X5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", true},
+		{"surrounding spaces", ChainEthereum, " 0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed\n", "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", true},
+		{"bad checksum", ChainEthereum, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", "", false},
+		{"mixed case without checksum", ChainEthereum, "0x5AAEB6053f3e94c9b9a09f33669435e7ef1beaed", "", false},
+		{"zero address", ChainEthereum, "0x0000000000000000000000000000000000000000", "", false},
+		{"no prefix", ChainEthereum, "5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "", false},
+		{"too short", ChainEthereum, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1bea", "", false},
+		{"not hex", ChainEthereum, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beagg", "", false},
+		{"bitcoin address on ethereum", ChainEthereum, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "", false},
+
+		// Base58Check
+		{"p2pkh", ChainBitcoin, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", true},
+		{"p2sh", ChainBitcoin, "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", "3J98t1WpEZ73CNmQviecrnyiWrnqRhWNLy", true},
+		{"p2pkh bad checksum", ChainBitcoin, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNb", "", false},
+		{"p2pkh wrong case", ChainBitcoin, "1a1zp1ep5qgefi2dmptftl5slmv7divfna", "", false},
+		{"testnet p2pkh", ChainBitcoin, "mipcBbFg9gMiCh81Kj8tqqdgoZub1ZJRfn", "", false},
+		{"not base58", ChainBitcoin, "1A1zP1eP5QGefi2DMPTfTL5SLmv7Divf0O", "", false},
+
+		// Bech32 (BIP 173) and Bech32m (BIP 350)
+		{"p2wpkh", ChainBitcoin, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", true},
+		{"p2wpkh upper case", ChainBitcoin, "BC1QW508D6QEJXTDG4Y5R3ZARVARY0C5XW7KV8F3T4", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", true},
+		{"p2tr", ChainBitcoin, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", true},
+		{"bech32 mixed case", ChainBitcoin, "bc1qW508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", "", false},
+		{"bech32 bad checksum", ChainBitcoin, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", "", false},
+		{"witness v0 with bech32m checksum", ChainBitcoin, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kemeawh", "", false},
+		{"witness v1 with bech32 checksum", ChainBitcoin, "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqh2y7hd", "", false},
+		{"testnet hrp", ChainBitcoin, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", "", false},
+		{"ethereum address on bitcoin", ChainBitcoin, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := ParseAddress(tt.chain, tt.in)
+			if !tt.valid {
+				if err == nil {
+					t.Fatalf("ParseAddress(%s, %q) = %q, want an error", tt.chain, tt.in, a.Key())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseAddress(%s, %q) failed: %v", tt.chain, tt.in, err)
+			}
+			if a.Key() != tt.key {
+				t.Errorf("ParseAddress(%s, %q).Key() = %q, want %q", tt.chain, tt.in, a.Key(), tt.key)
+			}
+		})
+	}
+}
+
+func TestParseAddressZeroAddress(t *testing.T) {
+	_, err := ParseAddress(ChainEthereum, "0x0000000000000000000000000000000000000000")
+	if errors.Cause(err) != ErrZeroAddress {
+		t.Errorf("ParseAddress of the zero address = %v, want %v", err, ErrZeroAddress)
+	}
+}
+
+func TestValidateBech32Address(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    string
+		valid bool
+	}{
+		{"mainnet", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", true},
+		// valid checksums, of other networks
+		{"testnet", "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", false},
+		{"testnet p2wsh", "tb1qrp33g0q5c5txsp9arysrx4k6zdkfs4nce4xj0gdcccefvpysxf3q0sl5k7", false},
+		{"regtest", "bcrt1q6rhpng9evdsfnn833a4f4vej0asu6dk5srld6x", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateBech32Address(tt.in); (err == nil) != tt.valid {
+				t.Errorf("validateBech32Address(%q) = %v, want valid %t", tt.in, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestDisplayAddress(t *testing.T) {
+	tests := []struct {
+		chain Chain
+		in    string
+		want  string
+	}{
+		{ChainEthereum, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{ChainEthereum, "0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359", "0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"},
+		{ChainEthereum, "0x1234", "0x1234"},
+		{ChainBitcoin, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"},
+		{ChainBitcoin, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4", "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+	}
+
+	for _, tt := range tests {
+		if got := DisplayAddress(tt.chain, tt.in); got != tt.want {
+			t.Errorf("DisplayAddress(%s, %q) = %q, want %q", tt.chain, tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Chain identifies the blockchain an address, transaction or report belongs to.
+type Chain string
+
+const (
+	ChainEthereum Chain = "eth"
+	ChainBSC      Chain = "bsc"
+	ChainPolygon  Chain = "polygon"
+	ChainGoerli   Chain = "goerli"
+	ChainSepolia  Chain = "sepolia"
+	ChainBitcoin  Chain = "btc"
+)
+
+// nativeSymbols are the tickers of the chains' native currencies.
+var nativeSymbols = map[Chain]string{
+	ChainEthereum: "ETH",
+	ChainBSC:      "BNB",
+	ChainPolygon:  "MATIC",
+	ChainGoerli:   "ETH",
+	ChainSepolia:  "ETH",
+	ChainBitcoin:  "BTC",
+}
+
+// nativeDecimals are the number of decimals of the chains' native currencies, i.e. the base units per coin.
+var nativeDecimals = map[Chain]uint8{
+	ChainEthereum: 18,
+	ChainBSC:      18,
+	ChainPolygon:  18,
+	ChainGoerli:   18,
+	ChainSepolia:  18,
+	ChainBitcoin:  8,
+}
+
+// Chains returns every supported chain.
+func Chains() []Chain {
+	return []Chain{ChainEthereum, ChainBSC, ChainPolygon, ChainGoerli, ChainSepolia, ChainBitcoin}
+}
+
+// ParseChain parses a chain identifier. An empty string defaults to ChainEthereum.
+func ParseChain(s string) (Chain, error) {
+	if s == "" {
+		return ChainEthereum, nil
+	}
+
+	c := Chain(strings.ToLower(s))
+	if _, ok := nativeSymbols[c]; !ok {
+		return "", fmt.Errorf("unknown chain %q", s)
+	}
+
+	return c, nil
+}
+
+// NativeSymbol returns the ticker of the chain's native currency.
+func (c Chain) NativeSymbol() string {
+	return nativeSymbols[c]
+}
+
+// NativeDecimals returns the number of decimals of the chain's native currency.
+func (c Chain) NativeDecimals() uint8 {
+	return nativeDecimals[c]
+}
+
+// UTXO returns true if the chain follows the UTXO model rather than the account model.
+func (c Chain) UTXO() bool {
+	return c == ChainBitcoin
+}
@@ -0,0 +1,146 @@
+package model
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// AssetNative identifies the chain's native currency, as opposed to a token contract.
+const AssetNative = "native"
+
+// Direction is the direction in which a crawl follows transactions.
+type Direction string
+
+const (
+	// DirectionForward follows transactions sent by an account, tracing where funds went.
+	DirectionForward Direction = "forward"
+	// DirectionBackward follows transactions received by an account, tracing where funds came from.
+	DirectionBackward Direction = "backward"
+	// DirectionBoth follows transactions in both directions.
+	DirectionBoth Direction = "both"
+)
+
+// ParseDirection parses a crawl direction. An empty string defaults to DirectionForward.
+func ParseDirection(s string) (Direction, error) {
+	switch d := Direction(s); d {
+	case "":
+		return DirectionForward, nil
+	case DirectionForward, DirectionBackward, DirectionBoth:
+		return d, nil
+	}
+
+	return "", fmt.Errorf("unknown crawl direction %q", s)
+}
+
+// Forward returns true if the direction follows outgoing transactions.
+func (d Direction) Forward() bool {
+	return d == DirectionForward || d == DirectionBoth
+}
+
+// Backward returns true if the direction follows incoming transactions.
+func (d Direction) Backward() bool {
+	return d == DirectionBackward || d == DirectionBoth
+}
+
+// Token describes an ERC-20 token contract.
+type Token struct {
+	Contract string `json:"contract"`
+	Symbol   string `json:"symbol"`
+	Decimals uint8  `json:"decimals"`
+}
+
+// Edge is a transaction moving funds from one address to another.
+type Edge struct {
+	Chain       Chain  `json:"chain"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	TxHash      string `json:"tx_hash"`
+	BlockNumber uint64 `json:"block_number"`
+	Timestamp   int64  `json:"timestamp"`
+
+	// Token is the token transferred, or nil if the edge moves the native currency.
+	Token *Token `json:"token,omitempty"`
+	// Amount is the amount transferred in the asset's base unit (e.g. wei or satoshis), as a decimal string.
+	Amount string `json:"amount"`
+	// Internal is true if the transfer was made by a contract during a transaction's execution, rather than by
+	// the transaction itself.
+	Internal bool `json:"internal"`
+
+	// Position tells apart the transfers of the same asset between the same addresses in one transaction: it is the
+	// log index of an ERC-20 Transfer log, in decimal, or the call-trace path of an internal transfer, the indices of
+	// the calls leading to it from the transaction's call joined by underscores, e.g. 0_2. It is empty if the source
+	// doesn't report it.
+	Position string `json:"position,omitempty"`
+
+	// Direction is the crawl direction the edge was discovered in.
+	Direction Direction `json:"direction"`
+}
+
+func NewEdge(chain Chain, from string, to string, txHash string, direction Direction) *Edge {
+	e := &Edge{
+		Chain:     chain,
+		From:      from,
+		To:        to,
+		TxHash:    txHash,
+		Direction: direction,
+	}
+
+	return e
+}
+
+// Key uniquely identifies the edge.
+func (e *Edge) Key() string {
+	key := e.TransferKey()
+	if e.Position != "" {
+		key += "_" + e.Position
+	}
+	return key
+}
+
+// TransferKey identifies the transfers of the edge's asset from its sender to its recipient in its transaction,
+// which Position tells apart.
+func (e *Edge) TransferKey() string {
+	key := string(e.Chain) + "_" + e.From + "_" + e.To + "_" + e.TxHash + "_" + e.Asset()
+	if e.Internal {
+		key += "_internal"
+	}
+	return key
+}
+
+// Asset returns the token contract the edge transfers, or AssetNative.
+func (e *Edge) Asset() string {
+	if e.Token == nil {
+		return AssetNative
+	}
+	return strings.ToLower(e.Token.Contract)
+}
+
+// Symbol returns the ticker of the asset the edge transfers.
+func (e *Edge) Symbol() string {
+	if e.Token == nil {
+		return e.Chain.NativeSymbol()
+	}
+	return e.Token.Symbol
+}
+
+// Value returns the amount transferred in the asset's base unit, or zero if it is unknown.
+func (e *Edge) Value() *big.Int {
+	v, ok := new(big.Int).SetString(e.Amount, 10)
+	if !ok {
+		return new(big.Int)
+	}
+	return v
+}
+
+// NormalizedAmount returns the amount transferred in whole units of the asset, i.e. scaled down by its decimals.
+func (e *Edge) NormalizedAmount() *big.Float {
+	decimals := int(e.Chain.NativeDecimals())
+	if e.Token != nil {
+		decimals = int(e.Token.Decimals)
+	}
+
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+
+	return new(big.Float).Quo(new(big.Float).SetInt(e.Value()), new(big.Float).SetInt(unit))
+}
@@ -1,49 +1,505 @@
 package model
 
-import "fmt"
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Graph is a directed multigraph of the transfers between the addresses of a chain. Any number of edges, one per
+// transfer, may link two addresses, and since funds are often sent back and forth the graph may contain cycles.
+type Graph struct {
+	Chain Chain
 
-type DAG struct {
 	vertices map[string]*Vertex
+
+	// out and in index the edges by sender and recipient, then by their other end, then by key
+	out map[string]map[string]map[string]*Edge
+	in  map[string]map[string]map[string]*Edge
+
+	edges int
 }
 
-func NewDAG() *DAG {
-	d := &DAG{
+func NewGraph(chain Chain) *Graph {
+	return &Graph{
+		Chain:    chain,
 		vertices: make(map[string]*Vertex),
+		out:      make(map[string]map[string]map[string]*Edge),
+		in:       make(map[string]map[string]map[string]*Edge),
+	}
+}
+
+// AddVertex adds address to the graph if it isn't in it yet, and returns its vertex.
+func (g *Graph) AddVertex(address string) *Vertex {
+	v, ok := g.vertices[address]
+	if !ok {
+		v = NewVertex(g.Chain, address)
+		g.vertices[address] = v
+	}
+
+	return v
+}
+
+// Vertex returns the vertex of address, or nil if address isn't in the graph.
+func (g *Graph) Vertex(address string) *Vertex {
+	return g.vertices[address]
+}
+
+// HasVertex returns whether address is in the graph.
+func (g *Graph) HasVertex(address string) bool {
+	_, ok := g.vertices[address]
+	return ok
+}
+
+// RemoveVertex removes address and all the edges it sent or received from the graph.
+func (g *Graph) RemoveVertex(address string) error {
+	if !g.HasVertex(address) {
+		return errors.Errorf("vertex %s does not exist", address)
+	}
+
+	for _, e := range g.OutEdges(address) {
+		g.removeEdge(e)
+	}
+	for _, e := range g.InEdges(address) {
+		g.removeEdge(e)
+	}
+
+	delete(g.vertices, address)
+	delete(g.out, address)
+	delete(g.in, address)
+
+	return nil
+}
+
+// Vertices returns the vertices of the graph, sorted by address.
+func (g *Graph) Vertices() []*Vertex {
+	vertices := make([]*Vertex, 0, len(g.vertices))
+	for _, address := range g.Addresses() {
+		vertices = append(vertices, g.vertices[address])
 	}
 
-	return d
+	return vertices
+}
+
+// Addresses returns the addresses of the graph, sorted.
+func (g *Graph) Addresses() []string {
+	addresses := make([]string, 0, len(g.vertices))
+	for address := range g.vertices {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	return addresses
+}
+
+// Len returns the number of vertices of the graph.
+func (g *Graph) Len() int {
+	return len(g.vertices)
+}
+
+// Size returns the number of edges of the graph.
+func (g *Graph) Size() int {
+	return g.edges
+}
+
+// AddEdge adds a transfer to the graph, adding its ends if they aren't in it yet. It returns false if the graph
+// already holds an edge with the same key.
+func (g *Graph) AddEdge(e *Edge) bool {
+	if g.HasEdge(e) {
+		return false
+	}
+
+	from, to := g.AddVertex(e.From), g.AddVertex(e.To)
+	from.Children[e.To] = struct{}{}
+	to.Parents[e.From] = struct{}{}
+
+	index(g.out, e.From, e.To)[e.Key()] = e
+	index(g.in, e.To, e.From)[e.Key()] = e
+	g.edges++
+
+	return true
 }
 
-func (d *DAG) AddVertex(v *Vertex) {
-	d.vertices[v.Address] = v
+// HasEdge returns whether the graph holds an edge with the same key as e.
+func (g *Graph) HasEdge(e *Edge) bool {
+	_, ok := g.out[e.From][e.To][e.Key()]
+	return ok
 }
 
-func (d *DAG) DeleteVertex(vertex *Vertex) error {
-	if _, ok := d.vertices[vertex.Address]; !ok {
-		return fmt.Errorf("vertex with ID %v does not exist", vertex.Address)
+// RemoveEdge removes a transfer from the graph. Its ends stay in the graph, even if no other edge touches them.
+func (g *Graph) RemoveEdge(e *Edge) error {
+	if !g.HasEdge(e) {
+		return errors.Errorf("edge %s does not exist", e.Key())
 	}
 
-	delete(d.vertices, vertex.Address)
+	g.removeEdge(e)
 
 	return nil
 }
 
-//func (d *DAG) AddEdge(parent *Vertex, child *Vertex) error {
-//	if _, ok := d.vertices[parent.Address]; !ok {
-//		return fmt.Errorf("vertex %v does not exist", parent.Address)
-//	}
-//
-//	if _, ok := d.vertices[child.Address]; !ok {
-//		return fmt.Errorf("vertex ID %v does not exist", child.Address)
-//	}
-//
-//	if _, ok := parent.Children[child.Address]; ok {
-//		return fmt.Errorf("edge (%v,%v) already exists", parent.Address, child.Address)
-//	}
-//
-//	// Add edge.
-//	parent.Children[child.Address] = struct{}{}
-//	child.Parents[parent.Address] = struct{}{}
-//
-//	return nil
-//}
\ No newline at end of file
+func (g *Graph) removeEdge(e *Edge) {
+	key := e.Key()
+
+	delete(g.out[e.From][e.To], key)
+	delete(g.in[e.To][e.From], key)
+	g.edges--
+
+	// the ends stay neighbors as long as another transfer links them
+	if len(g.out[e.From][e.To]) == 0 {
+		delete(g.out[e.From], e.To)
+		delete(g.in[e.To], e.From)
+		delete(g.vertices[e.From].Children, e.To)
+		delete(g.vertices[e.To].Parents, e.From)
+	}
+}
+
+func index(m map[string]map[string]map[string]*Edge, a string, b string) map[string]*Edge {
+	if m[a] == nil {
+		m[a] = make(map[string]map[string]*Edge)
+	}
+	if m[a][b] == nil {
+		m[a][b] = make(map[string]*Edge)
+	}
+
+	return m[a][b]
+}
+
+// Edges returns every edge of the graph, sorted by sender, recipient and key.
+func (g *Graph) Edges() []*Edge {
+	edges := make([]*Edge, 0, g.edges)
+	for _, address := range g.Addresses() {
+		edges = append(edges, g.OutEdges(address)...)
+	}
+
+	return edges
+}
+
+// EdgesBetween returns the edges sent by from to to, sorted by key.
+func (g *Graph) EdgesBetween(from string, to string) []*Edge {
+	return sortedEdges(g.out[from][to])
+}
+
+// OutEdges returns the edges sent by address, sorted by recipient and key.
+func (g *Graph) OutEdges(address string) []*Edge {
+	var edges []*Edge
+	for _, to := range sortedKeys(g.out[address]) {
+		edges = append(edges, sortedEdges(g.out[address][to])...)
+	}
+
+	return edges
+}
+
+// InEdges returns the edges received by address, sorted by sender and key.
+func (g *Graph) InEdges(address string) []*Edge {
+	var edges []*Edge
+	for _, from := range sortedKeys(g.in[address]) {
+		edges = append(edges, sortedEdges(g.in[address][from])...)
+	}
+
+	return edges
+}
+
+// OutDegree returns the number of edges sent by address.
+func (g *Graph) OutDegree(address string) int {
+	degree := 0
+	for _, edges := range g.out[address] {
+		degree += len(edges)
+	}
+
+	return degree
+}
+
+// InDegree returns the number of edges received by address.
+func (g *Graph) InDegree(address string) int {
+	degree := 0
+	for _, edges := range g.in[address] {
+		degree += len(edges)
+	}
+
+	return degree
+}
+
+// Successors returns the addresses address sent funds to, sorted.
+func (g *Graph) Successors(address string) []string {
+	return sortedKeys(g.out[address])
+}
+
+// Predecessors returns the addresses address received funds from, sorted.
+func (g *Graph) Predecessors(address string) []string {
+	return sortedKeys(g.in[address])
+}
+
+// Neighbors returns the addresses linked to address by an edge followed in the given direction, sorted.
+func (g *Graph) Neighbors(address string, direction Direction) []string {
+	if direction == DirectionBoth {
+		neighbors := make(map[string]struct{})
+		for to := range g.out[address] {
+			neighbors[to] = struct{}{}
+		}
+		for from := range g.in[address] {
+			neighbors[from] = struct{}{}
+		}
+
+		addresses := make([]string, 0, len(neighbors))
+		for neighbor := range neighbors {
+			addresses = append(addresses, neighbor)
+		}
+		sort.Strings(addresses)
+
+		return addresses
+	}
+
+	if direction.Backward() {
+		return g.Predecessors(address)
+	}
+
+	return g.Successors(address)
+}
+
+// Walk visits the vertices reachable from source breadth-first, following edges in the given direction. Each
+// vertex is visited once, with its distance in hops from source; returning false from visit stops the walk from
+// going past the vertex. A negative maxDepth doesn't bound the walk.
+func (g *Graph) Walk(source string, direction Direction, maxDepth int, visit func(address string, depth int) bool) {
+	if !g.HasVertex(source) {
+		return
+	}
+
+	depths := map[string]int{source: 0}
+	q := []string{source}
+
+	for len(q) != 0 {
+		u := q[0]
+		q = q[1:len(q):len(q)]
+
+		if !visit(u, depths[u]) {
+			continue
+		}
+		if maxDepth >= 0 && depths[u] >= maxDepth {
+			continue
+		}
+
+		for _, v := range g.Neighbors(u, direction) {
+			if _, ok := depths[v]; ok {
+				continue
+			}
+
+			depths[v] = depths[u] + 1
+			q = append(q, v)
+		}
+	}
+}
+
+// Filter returns a copy of the graph that only holds the edges keep returns true for. Every vertex is kept.
+func (g *Graph) Filter(keep func(e *Edge) bool) *Graph {
+	filtered := NewGraph(g.Chain)
+
+	for _, address := range g.Addresses() {
+		filtered.AddVertex(address)
+	}
+	for _, e := range g.Edges() {
+		if keep(e) {
+			filtered.AddEdge(e)
+		}
+	}
+
+	return filtered
+}
+
+// Induced returns the subgraph made of the given addresses and of the edges between them.
+func (g *Graph) Induced(addresses []string) *Graph {
+	induced := NewGraph(g.Chain)
+
+	for _, address := range addresses {
+		if g.HasVertex(address) {
+			induced.AddVertex(address)
+		}
+	}
+	for _, address := range induced.Addresses() {
+		for _, e := range g.OutEdges(address) {
+			if induced.HasVertex(e.To) {
+				induced.AddEdge(e)
+			}
+		}
+	}
+
+	return induced
+}
+
+// HasCycle returns whether funds sent from some address may flow back to it.
+func (g *Graph) HasCycle() bool {
+	return g.FindCycle() != nil
+}
+
+// FindCycle returns the addresses along a cycle of the graph, starting and ending with the same address, or nil if
+// the graph is acyclic. Self transfers are cycles of length one.
+func (g *Graph) FindCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+
+	state := make(map[string]int, len(g.vertices))
+	parent := make(map[string]string)
+
+	type frame struct {
+		address    string
+		successors []string
+	}
+
+	for _, root := range g.Addresses() {
+		if state[root] != unvisited {
+			continue
+		}
+
+		state[root] = visiting
+		stack := []*frame{{address: root, successors: g.Successors(root)}}
+
+		for len(stack) != 0 {
+			top := stack[len(stack)-1]
+
+			if len(top.successors) == 0 {
+				state[top.address] = done
+				stack = stack[:len(stack)-1]
+				continue
+			}
+
+			next := top.successors[0]
+			top.successors = top.successors[1:]
+
+			switch state[next] {
+			case visiting:
+				// next is on the stack: walk the parents back from top to it
+				cycle := []string{next}
+				for u := top.address; u != next; u = parent[u] {
+					cycle = append(cycle, u)
+				}
+				cycle = append(cycle, next)
+
+				for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+					cycle[i], cycle[j] = cycle[j], cycle[i]
+				}
+
+				return cycle
+			case unvisited:
+				state[next] = visiting
+				parent[next] = top.address
+				stack = append(stack, &frame{address: next, successors: g.Successors(next)})
+			}
+		}
+	}
+
+	return nil
+}
+
+// StronglyConnectedComponents returns the strongly connected components of the graph, i.e. the largest sets of
+// addresses that can all send funds to each other, using Tarjan's algorithm. Addresses in a component and the
+// components themselves are sorted.
+func (g *Graph) StronglyConnectedComponents() [][]string {
+	index := make(map[string]int, len(g.vertices))
+	lowlink := make(map[string]int, len(g.vertices))
+	onStack := make(map[string]bool)
+
+	var stack []string
+	var components [][]string
+
+	type frame struct {
+		address    string
+		successors []string
+	}
+
+	counter := 0
+	for _, root := range g.Addresses() {
+		if _, ok := index[root]; ok {
+			continue
+		}
+
+		// the recursion of Tarjan's algorithm is unrolled onto calls, as crawled graphs can be long chains
+		index[root], lowlink[root] = counter, counter
+		counter++
+		stack = append(stack, root)
+		onStack[root] = true
+
+		calls := []*frame{{address: root, successors: g.Successors(root)}}
+
+		for len(calls) != 0 {
+			top := calls[len(calls)-1]
+
+			if len(top.successors) != 0 {
+				next := top.successors[0]
+				top.successors = top.successors[1:]
+
+				if _, ok := index[next]; !ok {
+					index[next], lowlink[next] = counter, counter
+					counter++
+					stack = append(stack, next)
+					onStack[next] = true
+
+					calls = append(calls, &frame{address: next, successors: g.Successors(next)})
+				} else if onStack[next] && index[next] < lowlink[top.address] {
+					lowlink[top.address] = index[next]
+				}
+
+				continue
+			}
+
+			calls = calls[:len(calls)-1]
+			if len(calls) != 0 {
+				caller := calls[len(calls)-1].address
+				if lowlink[top.address] < lowlink[caller] {
+					lowlink[caller] = lowlink[top.address]
+				}
+			}
+
+			if lowlink[top.address] != index[top.address] {
+				continue
+			}
+
+			var component []string
+			for {
+				u := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[u] = false
+
+				component = append(component, u)
+				if u == top.address {
+					break
+				}
+			}
+
+			sort.Strings(component)
+			components = append(components, component)
+		}
+	}
+
+	sort.Slice(components, func(i, j int) bool {
+		return components[i][0] < components[j][0]
+	})
+
+	return components
+}
+
+func sortedKeys(m map[string]map[string]*Edge) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedEdges(m map[string]*Edge) []*Edge {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	edges := make([]*Edge, 0, len(m))
+	for _, k := range keys {
+		edges = append(edges, m[k])
+	}
+
+	return edges
+}
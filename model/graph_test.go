@@ -0,0 +1,184 @@
+package model
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testGraph builds a graph of chain ethereum from links, each given as a sender and recipient, with one transfer
+// per link.
+func testGraph(links ...[2]string) *Graph {
+	g := NewGraph(ChainEthereum)
+	for i, l := range links {
+		g.AddEdge(NewEdge(ChainEthereum, l[0], l[1], fmt.Sprintf("0x%x", i), DirectionForward))
+	}
+	return g
+}
+
+// chain returns the links of a path through n addresses.
+func chain(n int) [][2]string {
+	links := make([][2]string, n-1)
+	for i := range links {
+		links[i] = [2]string{chainAddress(i), chainAddress(i + 1)}
+	}
+	return links
+}
+
+func chainAddress(i int) string {
+	return fmt.Sprintf("a%06d", i)
+}
+
+// checkCycle fails t unless cycle is a cycle of g.
+func checkCycle(t *testing.T, g *Graph, cycle []string) {
+	t.Helper()
+
+	if len(cycle) < 2 || cycle[0] != cycle[len(cycle)-1] {
+		t.Fatalf("FindCycle() = %v, which doesn't end where it starts", cycle)
+	}
+
+	for i := 0; i < len(cycle)-1; i++ {
+		if len(g.EdgesBetween(cycle[i], cycle[i+1])) == 0 {
+			t.Fatalf("FindCycle() = %v, but %s sent nothing to %s", cycle, cycle[i], cycle[i+1])
+		}
+	}
+}
+
+func TestAddEdgeParallel(t *testing.T) {
+	g := NewGraph(ChainEthereum)
+
+	first := NewEdge(ChainEthereum, "a", "b", "0x1", DirectionForward)
+	if !g.AddEdge(first) {
+		t.Fatal("AddEdge() of a new edge = false")
+	}
+
+	// the same transfer, crawled again
+	if g.AddEdge(NewEdge(ChainEthereum, "a", "b", "0x1", DirectionForward)) {
+		t.Error("AddEdge() of a known edge = true")
+	}
+
+	// another transaction, and another transfer of the same transaction
+	g.AddEdge(NewEdge(ChainEthereum, "a", "b", "0x2", DirectionForward))
+	positioned := NewEdge(ChainEthereum, "a", "b", "0x2", DirectionForward)
+	positioned.Position = "3"
+	g.AddEdge(positioned)
+
+	if g.Size() != 3 {
+		t.Errorf("Size() = %d, want 3", g.Size())
+	}
+	if got := g.Successors("a"); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Errorf("Successors(a) = %v, want [b]", got)
+	}
+	if g.OutDegree("a") != 3 || g.InDegree("b") != 3 {
+		t.Errorf("OutDegree(a), InDegree(b) = %d, %d, want 3, 3", g.OutDegree("a"), g.InDegree("b"))
+	}
+
+	if err := g.RemoveEdge(first); err != nil {
+		t.Fatal(err)
+	}
+	if g.Size() != 2 || !g.HasVertex("a") {
+		t.Errorf("after RemoveEdge(), Size() = %d and HasVertex(a) = %t, want 2 and true", g.Size(), g.HasVertex("a"))
+	}
+}
+
+func TestFindCycle(t *testing.T) {
+	tests := []struct {
+		name   string
+		links  [][2]string
+		cyclic bool
+	}{
+		{"empty", nil, false},
+		{"chain", chain(5), false},
+		{"diamond", [][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}}, false},
+		{"self loop", [][2]string{{"a", "b"}, {"b", "b"}}, true},
+		{"back and forth", [][2]string{{"a", "b"}, {"b", "a"}}, true},
+		{"triangle off a chain", append(chain(4), [2]string{chainAddress(3), chainAddress(1)}), true},
+		{"parallel edges", [][2]string{{"a", "b"}, {"a", "b"}, {"b", "c"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := testGraph(tt.links...)
+
+			cycle := g.FindCycle()
+			if !tt.cyclic {
+				if cycle != nil {
+					t.Fatalf("FindCycle() = %v, want nil", cycle)
+				}
+				return
+			}
+
+			checkCycle(t, g, cycle)
+			if !g.HasCycle() {
+				t.Error("HasCycle() = false")
+			}
+		})
+	}
+}
+
+func TestFindCycleLongChain(t *testing.T) {
+	const n = 50000
+
+	links := append(chain(n), [2]string{chainAddress(n - 1), chainAddress(0)})
+	g := testGraph(links...)
+
+	cycle := g.FindCycle()
+	checkCycle(t, g, cycle)
+
+	if len(cycle) != n+1 {
+		t.Errorf("FindCycle() has %d addresses, want %d", len(cycle), n+1)
+	}
+
+	if testGraph(chain(n)...).HasCycle() {
+		t.Error("HasCycle() of a chain = true")
+	}
+}
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	tests := []struct {
+		name  string
+		links [][2]string
+		want  [][]string
+	}{
+		{"chain", chain(3), [][]string{{"a000000"}, {"a000001"}, {"a000002"}}},
+		{"self loop", [][2]string{{"a", "a"}, {"a", "b"}}, [][]string{{"a"}, {"b"}}},
+		{
+			"two cycles linked one way",
+			[][2]string{{"a", "b"}, {"b", "a"}, {"b", "c"}, {"c", "d"}, {"d", "e"}, {"e", "c"}},
+			[][]string{{"a", "b"}, {"c", "d", "e"}},
+		},
+		{
+			"parallel edges",
+			[][2]string{{"a", "b"}, {"a", "b"}, {"b", "a"}, {"b", "a"}, {"c", "a"}},
+			[][]string{{"a", "b"}, {"c"}},
+		},
+		{
+			"nested cycles",
+			[][2]string{{"a", "b"}, {"b", "c"}, {"c", "a"}, {"c", "d"}, {"d", "b"}, {"d", "e"}},
+			[][]string{{"a", "b", "c", "d"}, {"e"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testGraph(tt.links...).StronglyConnectedComponents(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("StronglyConnectedComponents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStronglyConnectedComponentsLongChain(t *testing.T) {
+	const n = 50000
+
+	components := testGraph(chain(n)...).StronglyConnectedComponents()
+	if len(components) != n {
+		t.Errorf("a chain of %d addresses has %d components, want %d", n, len(components), n)
+	}
+
+	links := append(chain(n), [2]string{chainAddress(n - 1), chainAddress(0)})
+	components = testGraph(links...).StronglyConnectedComponents()
+	if len(components) != 1 || len(components[0]) != n {
+		t.Errorf("a cycle of %d addresses has %d components, want 1 of them all", n, len(components))
+	}
+}
@@ -0,0 +1,131 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// ShortestPath returns the addresses along a path of fewest hops from one address to another, following edges from
+// sender to recipient, or nil if to can't be reached from from.
+func (g *Graph) ShortestPath(from string, to string) []string {
+	return g.shortestPath(from, to, nil, nil)
+}
+
+// shortestPath finds a path of fewest hops from one address to another, avoiding the given vertices and the links
+// between the given pairs of addresses.
+func (g *Graph) shortestPath(from string, to string, avoidVertices map[string]struct{}, avoidLinks map[[2]string]struct{}) []string {
+	if !g.HasVertex(from) || !g.HasVertex(to) {
+		return nil
+	}
+
+	parents := map[string]string{from: from}
+	q := []string{from}
+
+	for len(q) != 0 {
+		u := q[0]
+		q = q[1:len(q):len(q)]
+
+		if u == to {
+			path := []string{to}
+			for u != from {
+				u = parents[u]
+				path = append(path, u)
+			}
+
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+
+			return path
+		}
+
+		for _, v := range g.Successors(u) {
+			if _, ok := parents[v]; ok {
+				continue
+			}
+			if _, ok := avoidVertices[v]; ok {
+				continue
+			}
+			if _, ok := avoidLinks[[2]string{u, v}]; ok {
+				continue
+			}
+
+			parents[v] = u
+			q = append(q, v)
+		}
+	}
+
+	return nil
+}
+
+// KShortestPaths returns up to k loopless paths from one address to another, following edges from sender to
+// recipient, by increasing number of hops, using Yen's algorithm. Paths of the same length are sorted by address.
+func (g *Graph) KShortestPaths(from string, to string, k int) [][]string {
+	if k < 1 {
+		return nil
+	}
+
+	first := g.ShortestPath(from, to)
+	if first == nil {
+		return nil
+	}
+
+	paths := [][]string{first}
+	var candidates [][]string
+
+	seen := map[string]struct{}{pathKey(first): {}}
+
+	for len(paths) < k {
+		prev := paths[len(paths)-1]
+
+		// deviate from the previous path at each of its vertices in turn
+		for i := 0; i < len(prev)-1; i++ {
+			spur, root := prev[i], prev[:i+1]
+
+			avoidLinks := make(map[[2]string]struct{})
+			for _, p := range paths {
+				if len(p) > i+1 && pathKey(p[:i+1]) == pathKey(root) {
+					avoidLinks[[2]string{p[i], p[i+1]}] = struct{}{}
+				}
+			}
+
+			avoidVertices := make(map[string]struct{})
+			for _, u := range root[:i] {
+				avoidVertices[u] = struct{}{}
+			}
+
+			spurPath := g.shortestPath(spur, to, avoidVertices, avoidLinks)
+			if spurPath == nil {
+				continue
+			}
+
+			candidate := append(append([]string{}, root[:i]...), spurPath...)
+			if _, ok := seen[pathKey(candidate)]; ok {
+				continue
+			}
+			seen[pathKey(candidate)] = struct{}{}
+
+			candidates = append(candidates, candidate)
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		sort.Slice(candidates, func(i, j int) bool {
+			if len(candidates[i]) != len(candidates[j]) {
+				return len(candidates[i]) < len(candidates[j])
+			}
+			return pathKey(candidates[i]) < pathKey(candidates[j])
+		})
+
+		paths = append(paths, candidates[0])
+		candidates = candidates[1:]
+	}
+
+	return paths
+}
+
+func pathKey(path []string) string {
+	return strings.Join(path, ">")
+}
@@ -0,0 +1,98 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShortestPath(t *testing.T) {
+	g := testGraph([][2]string{{"a", "b"}, {"b", "c"}, {"c", "d"}, {"a", "c"}, {"d", "d"}, {"e", "a"}}...)
+
+	tests := []struct {
+		from, to string
+		want     []string
+	}{
+		{"a", "d", []string{"a", "c", "d"}},
+		{"a", "a", []string{"a"}},
+		{"d", "d", []string{"d"}},
+		{"d", "a", nil},
+		{"a", "unknown", nil},
+	}
+
+	for _, tt := range tests {
+		if got := g.ShortestPath(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ShortestPath(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestShortestPathLongChain(t *testing.T) {
+	const n = 50000
+
+	path := testGraph(chain(n)...).ShortestPath(chainAddress(0), chainAddress(n-1))
+	if len(path) != n || path[0] != chainAddress(0) || path[n-1] != chainAddress(n-1) {
+		t.Errorf("ShortestPath() along a chain of %d addresses has %d addresses", n, len(path))
+	}
+}
+
+func TestKShortestPaths(t *testing.T) {
+	diamond := [][2]string{{"a", "b"}, {"b", "d"}, {"a", "c"}, {"c", "d"}, {"a", "d"}, {"e", "a"}}
+
+	tests := []struct {
+		name  string
+		links [][2]string
+		to    string
+		k     int
+		want  [][]string
+	}{
+		{"zero k", diamond, "d", 0, nil},
+		{"unreachable", diamond, "e", 3, nil},
+		{"one", diamond, "d", 1, [][]string{{"a", "d"}}},
+		{"ties ordered by address", diamond, "d", 2, [][]string{{"a", "d"}, {"a", "b", "d"}}},
+		{"k larger than the paths", diamond, "d", 10, [][]string{{"a", "d"}, {"a", "b", "d"}, {"a", "c", "d"}}},
+		{
+			"parallel edges",
+			[][2]string{{"a", "b"}, {"a", "b"}, {"a", "b"}, {"b", "d"}, {"b", "d"}},
+			"d",
+			5,
+			[][]string{{"a", "b", "d"}},
+		},
+		{
+			"self loops",
+			[][2]string{{"a", "a"}, {"a", "b"}, {"b", "b"}, {"b", "d"}, {"d", "d"}},
+			"d",
+			5,
+			[][]string{{"a", "b", "d"}},
+		},
+		{
+			"cycles",
+			[][2]string{{"a", "b"}, {"b", "a"}, {"b", "c"}, {"c", "b"}, {"c", "d"}, {"a", "c"}},
+			"d",
+			5,
+			[][]string{{"a", "c", "d"}, {"a", "b", "c", "d"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testGraph(tt.links...).KShortestPaths("a", tt.to, tt.k); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("KShortestPaths(a, %s, %d) = %v, want %v", tt.to, tt.k, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKShortestPathsLongChain(t *testing.T) {
+	const n = 2000
+
+	// a chain with a shortcut around its middle
+	links := append(chain(n), [2]string{chainAddress(n/2 - 1), chainAddress(n/2 + 1)})
+
+	paths := testGraph(links...).KShortestPaths(chainAddress(0), chainAddress(n-1), 5)
+	if len(paths) != 2 {
+		t.Fatalf("KShortestPaths() = %d paths, want 2", len(paths))
+	}
+	if len(paths[0]) != n-1 || len(paths[1]) != n {
+		t.Errorf("KShortestPaths() have %d and %d addresses, want %d and %d", len(paths[0]), len(paths[1]), n-1, n)
+	}
+}
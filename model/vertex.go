@@ -5,13 +5,15 @@ import (
 )
 
 type Vertex struct {
+	Chain    Chain               `json:"chain"`
 	Address  string              `json:"address"`
 	Children map[string]struct{} `json:"children"`
 	Parents  map[string]struct{} `json:"parents"`
 }
 
-func NewVertex(address string) *Vertex {
+func NewVertex(chain Chain, address string) *Vertex {
 	v := &Vertex{
+		Chain:    chain,
 		Address:  address,
 		Parents:  make(map[string]struct{}),
 		Children: make(map[string]struct{}),
@@ -0,0 +1,114 @@
+package policy
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	"github.com/perlin-network/safu-go/log"
+)
+
+// DefaultReloadInterval is how often an engine checks its policy file for changes by default.
+const DefaultReloadInterval = 5 * time.Second
+
+// Engine holds the active policy, and reloads it when its file changes.
+type Engine struct {
+	path string
+
+	mu      sync.RWMutex
+	policy  *Policy
+	modTime time.Time
+	size    int64
+}
+
+// NewEngine returns an engine enforcing the policy of the file at path, or the default policy if path is empty.
+func NewEngine(path string) (*Engine, error) {
+	e := &Engine{path: path, policy: Default()}
+
+	if path == "" {
+		return e, nil
+	}
+
+	if _, err := e.Reload(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// Policy returns the active policy. Callers must not modify it, and should fetch it once per score, so that a
+// reload doesn't mix two policies in a score.
+func (e *Engine) Policy() *Policy {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.policy
+}
+
+// Reload reads the policy file again, and activates it if it is valid. The active policy is kept if it isn't.
+func (e *Engine) Reload() (*Policy, error) {
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := Load(e.path)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	e.policy = p
+	e.modTime = info.ModTime()
+	e.size = info.Size()
+	e.mu.Unlock()
+
+	return p, nil
+}
+
+// Watch reloads the policy file whenever it changes, checking every interval until stop is closed. Invalid files
+// are logged and ignored. It is a no-op for the default policy.
+func (e *Engine) Watch(interval time.Duration, stop <-chan struct{}) {
+	if e.path == "" {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(e.path)
+		if err != nil {
+			log.Warn().Err(err).Str("path", e.path).Msg("Unable to check the policy file.")
+			continue
+		}
+
+		e.mu.RLock()
+		changed := !info.ModTime().Equal(e.modTime) || info.Size() != e.size
+		e.mu.RUnlock()
+
+		if !changed {
+			continue
+		}
+
+		p, err := e.Reload()
+		if err != nil {
+			log.Warn().Err(err).Str("path", e.path).Msg("Kept the active policy: unable to reload the policy file.")
+
+			// don't retry until the file changes again
+			e.mu.Lock()
+			e.modTime = info.ModTime()
+			e.size = info.Size()
+			e.mu.Unlock()
+			continue
+		}
+
+		log.Info().Str("path", e.path).Str("version", p.Version).Msg("Reloaded the policy.")
+	}
+}
@@ -0,0 +1,446 @@
+// Package policy configures how taint spreads and how addresses are scored: component weights and caps, decay,
+// the scoring model and thresholds. Policies are read from TOML or YAML files, on top of the defaults, so a file
+// only needs to set what it changes.
+package policy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/perlin-network/safu-go/label"
+	"github.com/perlin-network/safu-go/score"
+	"github.com/perlin-network/safu-go/taint"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// Model is how the taint score of an address is computed.
+type Model string
+
+const (
+	// ModelReports scores addresses by the reports against them and the reputation of their reporters.
+	ModelReports Model = "reports"
+
+	// ModelGraphRisk scores addresses by their graph risk alone.
+	ModelGraphRisk Model = "graph_risk"
+
+	// ModelCombined adds the graph risk to the reports and reputation.
+	ModelCombined Model = "combined"
+)
+
+// DefaultVersion is the version of the default policy.
+const DefaultVersion = "default"
+
+// Duration is a time.Duration written as a Go duration string, such as 8760h, in policy files.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+
+	return d.UnmarshalText([]byte(s))
+}
+
+// Component is a part of the taint score: the component's input times Weight, capped at Cap.
+type Component struct {
+	Weight float64 `toml:"weight" yaml:"weight" json:"weight"`
+	Cap    float64 `toml:"cap" yaml:"cap" json:"cap"`
+}
+
+// Score returns the component's part of the taint score for input, from 0 to Cap.
+func (c Component) Score(input float64) float64 {
+	return math.Max(0, math.Min(c.Cap, input*c.Weight))
+}
+
+// Components are the parts the taint score adds up, each capped separately.
+type Components struct {
+	// Reports weighs the aggregated score of the reports against the address, from 0 to 100.
+	Reports Component `toml:"reports" yaml:"reports" json:"reports"`
+
+	// Reputation weighs the reputation of the reporters, in reputation points.
+	Reputation Component `toml:"reputation" yaml:"reputation" json:"reputation"`
+
+	// GraphRisk weighs the graph risk of the address, from 0 to 100.
+	GraphRisk Component `toml:"graph_risk" yaml:"graph_risk" json:"graph_risk"`
+}
+
+// Propagation tunes how taint spreads over the transaction graph.
+type Propagation struct {
+	// ChildrenRatio is the share of the source's taint held by the addresses its funds reach.
+	ChildrenRatio float64 `toml:"children_ratio" yaml:"children_ratio" json:"children_ratio"`
+
+	// MinLabelConfidence is the confidence a label needs to stop taint.
+	MinLabelConfidence float64 `toml:"min_label_confidence" yaml:"min_label_confidence" json:"min_label_confidence"`
+
+	// AllowlistCap is the highest taint an allowlisted address holds.
+	AllowlistCap int `toml:"allowlist_cap" yaml:"allowlist_cap" json:"allowlist_cap"`
+}
+
+// Reputation tunes how the reputation of reporters weighs their reports.
+type Reputation struct {
+	// Step is the weight a point of reputation adds to a reporter's reports, and MaxWeight bounds the weight of the
+	// reports of the most reputable reporters.
+	Step      float64 `toml:"step" yaml:"step" json:"step"`
+	MaxWeight float64 `toml:"max_weight" yaml:"max_weight" json:"max_weight"`
+}
+
+// Band is a risk band: the taint scores from Min up to the next band's Min.
+type Band struct {
+	Name string `toml:"name" yaml:"name" json:"name"`
+	Min  int    `toml:"min" yaml:"min" json:"min"`
+}
+
+// Thresholds are scores that override the scoring model.
+type Thresholds struct {
+	// SanctionedScore is the lowest taint score of sanctioned addresses.
+	SanctionedScore int `toml:"sanctioned_score" yaml:"sanctioned_score" json:"sanctioned_score"`
+}
+
+// Policy configures scoring.
+type Policy struct {
+	// Version identifies the policy in scores. It defaults to a digest of the policy file.
+	Version string `toml:"version" yaml:"version" json:"version"`
+
+	Model Model `toml:"model" yaml:"model" json:"model"`
+
+	// HalfLife is the time reports and taint take to lose half their weight. They don't decay if it is 0.
+	HalfLife Duration `toml:"half_life" yaml:"half_life" json:"half_life"`
+
+	Components  Components  `toml:"components" yaml:"components" json:"components"`
+	Propagation Propagation `toml:"propagation" yaml:"propagation" json:"propagation"`
+
+	// ReportStatus holds the weight of the reports of each status. Reports of other statuses, such as rejected and
+	// retracted reports, don't count. Setting the weight of every status but verified to 0 only counts verified
+	// reports.
+	ReportStatus map[string]float64 `toml:"report_status" yaml:"report_status" json:"report_status"`
+
+	Reputation Reputation `toml:"reputation" yaml:"reputation" json:"reputation"`
+	Thresholds Thresholds `toml:"thresholds" yaml:"thresholds" json:"thresholds"`
+
+	// Bands are the risk bands taint scores fall into, sorted by Min from 0. A file setting bands replaces the
+	// default bands.
+	Bands []Band `toml:"bands" yaml:"bands" json:"bands"`
+
+	// Categories holds the keywords that tag an address with a category when a report title against it contains
+	// one, regardless of case. Addresses are also tagged with the categories of their labels, except custom.
+	Categories map[string][]string `toml:"categories" yaml:"categories" json:"categories"`
+}
+
+// Default returns the default policy.
+func Default() *Policy {
+	return &Policy{
+		Version:  DefaultVersion,
+		Model:    ModelReports,
+		HalfLife: Duration(taint.DefaultHalfLife),
+		Components: Components{
+			Reports:    Component{Weight: 0.7, Cap: 70},
+			Reputation: Component{Weight: 10, Cap: 30},
+			GraphRisk:  Component{Weight: 0.3, Cap: 30},
+		},
+		Propagation: Propagation{
+			ChildrenRatio:      taint.ChildrenRatio,
+			MinLabelConfidence: label.MinConfidence,
+			AllowlistCap:       10,
+		},
+		ReportStatus: map[string]float64{
+			"submitted":    0.5,
+			"under_review": 0.5,
+			"verified":     1,
+			"disputed":     0.25,
+		},
+		Reputation: Reputation{
+			Step:      score.DefaultReputationStep,
+			MaxWeight: score.DefaultMaxReputationWeight,
+		},
+		Thresholds: Thresholds{
+			SanctionedScore: 100,
+		},
+		Bands: []Band{
+			{Name: "low", Min: 0},
+			{Name: "medium", Min: 25},
+			{Name: "high", Min: 50},
+			{Name: "severe", Min: 75},
+		},
+		Categories: map[string][]string{
+			"phishing":      {"phish", "fake site", "fake website", "impersonat", "drainer", "seed phrase"},
+			"ponzi":         {"ponzi", "pyramid", "mlm", "hyip", "high yield"},
+			"exchange_hack": {"exchange hack", "hacked", "hack", "exploit", "stolen"},
+			"rug_pull":      {"rug pull", "rugpull", "exit scam"},
+		},
+	}
+}
+
+// Load reads a policy from a .toml, .yaml or .yml file.
+func Load(path string) (*Policy, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := Parse(b, filepath.Ext(path))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid policy %s", path)
+	}
+
+	return p, nil
+}
+
+// Parse reads a policy from the contents of a file with the given extension. Settings the file doesn't set keep
+// their default.
+func Parse(b []byte, ext string) (*Policy, error) {
+	p := Default()
+	p.Version = ""
+
+	switch strings.ToLower(ext) {
+	case ".toml":
+		md, err := toml.DecodeReader(bytes.NewReader(b), p)
+		if err != nil {
+			return nil, err
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return nil, errors.Errorf("unknown policy setting %s", undecoded[0])
+		}
+	case ".yaml", ".yml":
+		if err := yaml.UnmarshalStrict(b, p); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unknown policy file extension %q: expected .toml, .yaml or .yml", ext)
+	}
+
+	if p.Version == "" {
+		digest := sha256.Sum256(b)
+		p.Version = hex.EncodeToString(digest[:6])
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Validate checks that the policy's settings are within their bounds.
+func (p *Policy) Validate() error {
+	switch p.Model {
+	case ModelReports, ModelGraphRisk, ModelCombined:
+	default:
+		return errors.Errorf("unknown model %q", p.Model)
+	}
+
+	if p.HalfLife < 0 {
+		return errors.New("half_life must not be negative")
+	}
+
+	components := []struct {
+		name string
+		Component
+	}{
+		{"reports", p.Components.Reports},
+		{"reputation", p.Components.Reputation},
+		{"graph_risk", p.Components.GraphRisk},
+	}
+
+	for _, c := range components {
+		if c.Weight < 0 || c.Cap < 0 || c.Cap > 100 {
+			return errors.Errorf("component %s must have a non-negative weight and a cap from 0 to 100", c.name)
+		}
+	}
+
+	if p.Propagation.ChildrenRatio <= 0 || p.Propagation.ChildrenRatio > 1 {
+		return errors.New("children_ratio must be greater than 0, and at most 1")
+	}
+
+	if p.Propagation.MinLabelConfidence < 0 || p.Propagation.MinLabelConfidence > 1 {
+		return errors.New("min_label_confidence must be from 0 to 1")
+	}
+
+	if p.Propagation.AllowlistCap < 0 || p.Propagation.AllowlistCap > 100 {
+		return errors.New("allowlist_cap must be from 0 to 100")
+	}
+
+	for status, weight := range p.ReportStatus {
+		if weight < 0 {
+			return errors.Errorf("report status %s has a negative weight", status)
+		}
+	}
+
+	if p.Reputation.Step < 0 || p.Reputation.MaxWeight < 0 {
+		return errors.New("reputation step and max_weight must not be negative")
+	}
+
+	if p.Thresholds.SanctionedScore < 0 || p.Thresholds.SanctionedScore > 100 {
+		return errors.New("sanctioned_score must be from 0 to 100")
+	}
+
+	if len(p.Bands) == 0 || p.Bands[0].Min != 0 {
+		return errors.New("bands must start with a band from 0")
+	}
+
+	names := make(map[string]struct{})
+	for i, band := range p.Bands {
+		if band.Name == "" {
+			return errors.Errorf("band %d has no name", i)
+		}
+		if _, ok := names[band.Name]; ok {
+			return errors.Errorf("band %s is defined twice", band.Name)
+		}
+		names[band.Name] = struct{}{}
+
+		if i > 0 && band.Min <= p.Bands[i-1].Min {
+			return errors.New("bands must be sorted by min, without duplicates")
+		}
+	}
+
+	return nil
+}
+
+// Weights returns the weights reports are aggregated with.
+func (p *Policy) Weights() score.Weights {
+	return score.Weights{
+		HalfLife:            time.Duration(p.HalfLife),
+		Status:              p.ReportStatus,
+		ReputationStep:      p.Reputation.Step,
+		MaxReputationWeight: p.Reputation.MaxWeight,
+	}
+}
+
+// TaintOptions returns the options taint spreads with. The caller adds the entities, barriers and caps of the graph
+// taint spreads over.
+func (p *Policy) TaintOptions() taint.Options {
+	return taint.Options{ChildrenRatio: p.Propagation.ChildrenRatio}
+}
+
+// Inputs are what an address is scored from.
+type Inputs struct {
+	// Reports is the aggregated score of the reports against the address, from 0 to 100.
+	Reports float64
+
+	// Reputation is the reputation of the reporters, decayed.
+	Reputation float64
+
+	// GraphRisk is the graph risk of the address, from 0 to 100.
+	GraphRisk float64
+
+	Sanctioned  bool
+	Allowlisted bool
+}
+
+// Breakdown is the taint score of an address, with the part each component takes in it.
+type Breakdown struct {
+	Score int `json:"score"`
+
+	Reports    float64 `json:"reports"`
+	Reputation float64 `json:"reputation"`
+	GraphRisk  float64 `json:"graph_risk"`
+}
+
+// Score scores an address. Each component is capped before they are added up, and the sum is capped at 100.
+// Allowlisted addresses score at most the allowlist cap, but sanctioned addresses score at least the sanctioned
+// threshold even if allowlisted: sanctions aren't a false positive to suppress.
+func (p *Policy) Score(in Inputs) *Breakdown {
+	b := &Breakdown{}
+
+	if p.Model == ModelReports || p.Model == ModelCombined {
+		b.Reports = p.Components.Reports.Score(in.Reports)
+		b.Reputation = p.Components.Reputation.Score(in.Reputation)
+	}
+
+	if p.Model == ModelGraphRisk || p.Model == ModelCombined {
+		b.GraphRisk = p.Components.GraphRisk.Score(in.GraphRisk)
+	}
+
+	// reputation only vouches for reports: it doesn't taint an address nobody reported
+	if b.Reports == 0 {
+		b.Reputation = 0
+	}
+
+	b.Score = int(math.Round(math.Min(100, b.Reports+b.Reputation+b.GraphRisk)))
+
+	if in.Allowlisted {
+		b.Score = p.CapAllowlisted(b.Score)
+	}
+
+	if in.Sanctioned && b.Score < p.Thresholds.SanctionedScore {
+		b.Score = p.Thresholds.SanctionedScore
+	}
+
+	return b
+}
+
+// CapAllowlisted caps the taint of an allowlisted address.
+func (p *Policy) CapAllowlisted(taint int) int {
+	if taint > p.Propagation.AllowlistCap {
+		return p.Propagation.AllowlistCap
+	}
+	return taint
+}
+
+// Band returns the name of the risk band score falls into.
+func (p *Policy) Band(score int) string {
+	band := p.Bands[0].Name
+	for _, b := range p.Bands {
+		if score >= b.Min {
+			band = b.Name
+		}
+	}
+
+	return band
+}
+
+// Categorize tags an address with the categories the titles of the reports against it and its labels point to,
+// sorted.
+func (p *Policy) Categorize(titles []string, labels []*label.Label) []string {
+	tags := make(map[string]struct{})
+
+	for _, title := range titles {
+		title = strings.ToLower(title)
+
+		for category, keywords := range p.Categories {
+			for _, keyword := range keywords {
+				if keyword != "" && strings.Contains(title, strings.ToLower(keyword)) {
+					tags[category] = struct{}{}
+					break
+				}
+			}
+		}
+	}
+
+	for _, l := range labels {
+		if l.Category != label.CategoryCustom {
+			tags[string(l.Category)] = struct{}{}
+		}
+	}
+
+	categories := make([]string, 0, len(tags))
+	for tag := range tags {
+		categories = append(categories, tag)
+	}
+	sort.Strings(categories)
+
+	return categories
+}
@@ -0,0 +1,134 @@
+package rank
+
+import (
+	"math"
+
+	"github.com/perlin-network/safu-go/model"
+)
+
+const (
+	// Damping is the probability that the random walk follows a transfer rather than jumping back to a seed.
+	Damping = 0.85
+
+	// MaxIterations bounds the number of power iterations.
+	MaxIterations = 100
+
+	// Tolerance is the L1 distance between two iterations below which the ranks are deemed to have converged.
+	Tolerance = 1e-9
+)
+
+// PersonalizedPageRank ranks the addresses of g by how much of the funds sent by the seeds may reach them. It is
+// the stationary distribution of a random walk that follows transfers from sender to recipient with a probability
+// proportional to the value transferred, and jumps back to a seed, chosen in proportion to its weight, with
+// probability 1 - Damping or whenever it reaches an address that didn't send anything. Values are compared in whole
+// units of their asset. Ranks sum to 1.
+func PersonalizedPageRank(g *model.Graph, seeds map[string]float64) map[string]float64 {
+	addresses := g.Addresses()
+
+	teleport := make(map[string]float64)
+	var total float64
+	for address, weight := range seeds {
+		if g.HasVertex(address) && weight > 0 {
+			teleport[address] = weight
+			total += weight
+		}
+	}
+	if total == 0 {
+		return map[string]float64{}
+	}
+	for address := range teleport {
+		teleport[address] /= total
+	}
+
+	// the transition probabilities of every sender
+	transitions := make(map[string]map[string]float64, len(addresses))
+	for _, u := range addresses {
+		weights := make(map[string]float64)
+		var sum float64
+
+		for _, v := range g.Successors(u) {
+			var w float64
+			for _, e := range g.EdgesBetween(u, v) {
+				value, _ := e.NormalizedAmount().Float64()
+				w += value
+			}
+
+			weights[v] = w
+			sum += w
+		}
+
+		// transfers without value, e.g. contract calls, are followed uniformly
+		if sum == 0 {
+			for v := range weights {
+				weights[v] = 1
+				sum++
+			}
+		}
+
+		for v := range weights {
+			weights[v] /= sum
+		}
+
+		if len(weights) != 0 {
+			transitions[u] = weights
+		}
+	}
+
+	ranks := make(map[string]float64, len(teleport))
+	for address, p := range teleport {
+		ranks[address] = p
+	}
+
+	for i := 0; i < MaxIterations; i++ {
+		next := make(map[string]float64, len(ranks))
+
+		var dangling float64
+		for u, r := range ranks {
+			weights, ok := transitions[u]
+			if !ok {
+				dangling += r
+				continue
+			}
+
+			for v, p := range weights {
+				next[v] += Damping * r * p
+			}
+		}
+
+		jump := (1 - Damping) + Damping*dangling
+		for address, p := range teleport {
+			next[address] += jump * p
+		}
+
+		var delta float64
+		for _, address := range addresses {
+			delta += math.Abs(next[address] - ranks[address])
+		}
+
+		ranks = next
+		if delta < Tolerance {
+			break
+		}
+	}
+
+	return ranks
+}
+
+// Normalize scales ranks to scores from 0 to 100, the highest rank scoring 100.
+func Normalize(ranks map[string]float64) map[string]float64 {
+	var max float64
+	for _, r := range ranks {
+		if r > max {
+			max = r
+		}
+	}
+
+	scores := make(map[string]float64, len(ranks))
+	for address, r := range ranks {
+		if max > 0 {
+			scores[address] = 100 * r / max
+		}
+	}
+
+	return scores
+}
@@ -0,0 +1,152 @@
+// Package score combines the reports against an address into a single score.
+//
+// Every report carries the taint its target held when it was last propagated, from 0 to 100. A report's weight is
+// the product of three factors:
+//
+//   - its status weight, so that e.g. rejected reports don't count;
+//   - its recency, from 1 when it is submitted, halving every half-life;
+//   - its reporter's reputation: 1 for a neutral reporter, plus the reputation step per point of reputation, within
+//     [0, the maximum reputation weight].
+//
+// A reporter counts once: of its reports against an address, only the one with the highest weighted taint counts.
+// The weighted taints t of the reporters are then combined as independent pieces of evidence:
+//
+//	score = 100 × (1 - Π(1 - t/100))
+//
+// so every report raises the score, no report lowers it, and the score never exceeds 100. The product doesn't depend
+// on the order of the reports, and ties between reports of a reporter are broken by report ID, so the score only
+// depends on the set of reports.
+package score
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/perlin-network/safu-go/taint"
+)
+
+const (
+	// DefaultReputationStep is the default weight a point of reputation adds to a reporter's reports.
+	DefaultReputationStep = 0.1
+
+	// DefaultMaxReputationWeight is the default bound on the weight of the reports of the most reputable reporters.
+	DefaultMaxReputationWeight = 2.0
+)
+
+// Report is a report against an address, as scored.
+type Report struct {
+	ID       string
+	Reporter string
+
+	// Taint is the taint the reported address held when it was last propagated, from 0 to 100.
+	Taint int
+
+	// Timestamp is the unix timestamp, in seconds, the report was submitted at.
+	Timestamp int64
+
+	Status string
+
+	// Reputation is the reporter's reputation.
+	Reputation int
+}
+
+// Weights tune how reports are weighted.
+type Weights struct {
+	// HalfLife is the time a report takes to lose half its weight. Reports don't decay if it is 0.
+	HalfLife time.Duration
+
+	// Status holds the weight of the reports of each status. Reports of other statuses don't count.
+	Status map[string]float64
+
+	// ReputationStep is the weight a point of reputation adds to a reporter's reports, and MaxReputationWeight
+	// bounds the weight of the reports of the most reputable reporters.
+	ReputationStep      float64
+	MaxReputationWeight float64
+}
+
+// Contribution is the part a report takes in a score.
+type Contribution struct {
+	ReportID string `json:"report_id"`
+	Reporter string `json:"reporter"`
+
+	// Status is the status of the report, and Weight its weight given its status, age and reporter.
+	Status string  `json:"status"`
+	Weight float64 `json:"weight"`
+
+	// Taint is the report's weighted taint.
+	Taint float64 `json:"taint"`
+
+	// Counted is false for the reports outweighed by another report of the same reporter.
+	Counted bool `json:"counted"`
+}
+
+// Result is the score of the reports against an address.
+type Result struct {
+	// Score is from 0 to 100.
+	Score float64
+
+	// Contributions lists every report submitted by asOf, sorted by report ID.
+	Contributions []*Contribution
+}
+
+// Aggregate scores reports as of the unix timestamp asOf. Reports submitted after asOf are ignored.
+func Aggregate(reports []*Report, asOf int64, w Weights) *Result {
+	res := &Result{Contributions: []*Contribution{}}
+
+	best := make(map[string]*Contribution)
+
+	sorted := append([]*Report{}, reports...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	for _, r := range sorted {
+		if r.Timestamp > asOf {
+			continue
+		}
+
+		weight := w.Status[r.Status] * taint.DecayFactor(r.Timestamp, asOf, w.HalfLife) * w.ReputationWeight(r.Reputation)
+
+		c := &Contribution{
+			ReportID: r.ID,
+			Reporter: r.Reporter,
+			Status:   r.Status,
+			Weight:   weight,
+			Taint:    weight * float64(clamp(r.Taint, 0, 100)),
+		}
+		res.Contributions = append(res.Contributions, c)
+
+		// reports are visited by ID, so the earliest ID wins ties
+		if b, ok := best[r.Reporter]; !ok || c.Taint > b.Taint {
+			best[r.Reporter] = c
+		}
+	}
+
+	clean := 1.0
+	for _, c := range res.Contributions {
+		if best[c.Reporter] != c {
+			continue
+		}
+
+		c.Counted = true
+		clean *= 1 - math.Min(c.Taint, 100)/100
+	}
+
+	res.Score = 100 * (1 - clean)
+
+	return res
+}
+
+// ReputationWeight returns the weight of the reports of a reporter of the given reputation.
+func (w Weights) ReputationWeight(reputation int) float64 {
+	return math.Max(0, math.Min(w.MaxReputationWeight, 1+w.ReputationStep*float64(reputation)))
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
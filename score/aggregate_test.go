@@ -0,0 +1,258 @@
+package score
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const epsilon = 1e-9
+
+// testWeights counts submitted reports in full and verified ones twice, without decay, and without weighing
+// reputation unless a test asks to.
+var testWeights = Weights{
+	Status: map[string]float64{
+		"submitted": 1,
+		"verified":  2,
+		"disputed":  0.5,
+	},
+	MaxReputationWeight: DefaultMaxReputationWeight,
+}
+
+func TestAggregate(t *testing.T) {
+	const (
+		asOf = int64(1000000)
+		day  = int64(24 * 60 * 60)
+	)
+
+	halving := testWeights
+	halving.HalfLife = 24 * time.Hour
+
+	reputable := testWeights
+	reputable.ReputationStep = DefaultReputationStep
+
+	tests := []struct {
+		name    string
+		reports []*Report
+		weights Weights
+		want    float64
+		counted []string
+	}{
+		{"no reports", nil, testWeights, 0, nil},
+		{
+			"one report",
+			[]*Report{{ID: "a", Reporter: "x", Taint: 40, Timestamp: asOf, Status: "submitted"}},
+			testWeights,
+			40,
+			[]string{"a"},
+		},
+		{
+			"independent reporters",
+			[]*Report{
+				{ID: "a", Reporter: "x", Taint: 50, Timestamp: asOf, Status: "submitted"},
+				{ID: "b", Reporter: "y", Taint: 50, Timestamp: asOf, Status: "submitted"},
+			},
+			testWeights,
+			75,
+			[]string{"a", "b"},
+		},
+		{
+			"a reporter counts once",
+			[]*Report{
+				{ID: "a", Reporter: "x", Taint: 50, Timestamp: asOf, Status: "submitted"},
+				{ID: "b", Reporter: "x", Taint: 30, Timestamp: asOf, Status: "submitted"},
+				{ID: "c", Reporter: "x", Taint: 50, Timestamp: asOf, Status: "submitted"},
+			},
+			testWeights,
+			50,
+			[]string{"a"},
+		},
+		{
+			"the weightiest report of a reporter counts",
+			[]*Report{
+				{ID: "a", Reporter: "x", Taint: 30, Timestamp: asOf, Status: "verified"},
+				{ID: "b", Reporter: "x", Taint: 50, Timestamp: asOf, Status: "submitted"},
+			},
+			testWeights,
+			60,
+			[]string{"a"},
+		},
+		{
+			"statuses weigh reports",
+			[]*Report{
+				{ID: "a", Reporter: "x", Taint: 40, Timestamp: asOf, Status: "disputed"},
+				{ID: "b", Reporter: "y", Taint: 90, Timestamp: asOf, Status: "rejected"},
+				{ID: "c", Reporter: "z", Taint: 90, Timestamp: asOf, Status: "retracted"},
+			},
+			testWeights,
+			20,
+			[]string{"a", "b", "c"},
+		},
+		{
+			"weighted taint is capped",
+			[]*Report{{ID: "a", Reporter: "x", Taint: 80, Timestamp: asOf, Status: "verified"}},
+			testWeights,
+			100,
+			[]string{"a"},
+		},
+		{
+			"taint is clamped",
+			[]*Report{{ID: "a", Reporter: "x", Taint: 250, Timestamp: asOf, Status: "submitted"}},
+			testWeights,
+			100,
+			[]string{"a"},
+		},
+		{
+			"reports decay",
+			[]*Report{
+				{ID: "a", Reporter: "x", Taint: 80, Timestamp: asOf - day, Status: "submitted"},
+				{ID: "b", Reporter: "y", Taint: 80, Timestamp: asOf - 2*day, Status: "submitted"},
+			},
+			halving,
+			100 * (1 - 0.6*0.8),
+			[]string{"a", "b"},
+		},
+		{
+			"later reports are ignored",
+			[]*Report{
+				{ID: "a", Reporter: "x", Taint: 40, Timestamp: asOf, Status: "submitted"},
+				{ID: "b", Reporter: "x", Taint: 90, Timestamp: asOf + 1, Status: "submitted"},
+			},
+			testWeights,
+			40,
+			[]string{"a"},
+		},
+		{
+			"reputation weighs reports",
+			[]*Report{
+				{ID: "a", Reporter: "x", Taint: 40, Timestamp: asOf, Status: "submitted", Reputation: 5},
+				{ID: "b", Reporter: "y", Taint: 40, Timestamp: asOf, Status: "submitted", Reputation: -5},
+			},
+			reputable,
+			100 * (1 - 0.4*0.8),
+			[]string{"a", "b"},
+		},
+		{
+			"reputation weight is bounded",
+			[]*Report{
+				{ID: "a", Reporter: "x", Taint: 10, Timestamp: asOf, Status: "submitted", Reputation: 100},
+				{ID: "b", Reporter: "y", Taint: 90, Timestamp: asOf, Status: "submitted", Reputation: -100},
+			},
+			reputable,
+			20,
+			[]string{"a", "b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := Aggregate(tt.reports, asOf, tt.weights)
+
+			if math.Abs(res.Score-tt.want) > epsilon {
+				t.Errorf("Score = %v, want %v", res.Score, tt.want)
+			}
+
+			var counted []string
+			for _, c := range res.Contributions {
+				if c.Counted {
+					counted = append(counted, c.ReportID)
+				}
+			}
+
+			if !reflect.DeepEqual(counted, tt.counted) {
+				t.Errorf("counted reports = %v, want %v", counted, tt.counted)
+			}
+		})
+	}
+}
+
+// randomReports returns n reports of a few reporters, of every status, submitted over the last few days.
+func randomReports(rng *rand.Rand, n int, asOf int64) []*Report {
+	statuses := []string{"submitted", "verified", "disputed", "rejected"}
+
+	reports := make([]*Report, n)
+	for i := range reports {
+		reports[i] = &Report{
+			ID:         string(rune('a' + i)),
+			Reporter:   string(rune('p' + rng.Intn(4))),
+			Taint:      rng.Intn(101),
+			Timestamp:  asOf - rng.Int63n(5*24*60*60),
+			Status:     statuses[rng.Intn(len(statuses))],
+			Reputation: rng.Intn(21) - 10,
+		}
+	}
+
+	return reports
+}
+
+func TestAggregatePermutations(t *testing.T) {
+	const asOf = int64(1000000)
+
+	w := testWeights
+	w.HalfLife = 24 * time.Hour
+	w.ReputationStep = DefaultReputationStep
+
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 100; i++ {
+		reports := randomReports(rng, 1+rng.Intn(12), asOf)
+		want := Aggregate(reports, asOf, w)
+
+		for j := 0; j < 10; j++ {
+			shuffled := append([]*Report{}, reports...)
+			rng.Shuffle(len(shuffled), func(a, b int) { shuffled[a], shuffled[b] = shuffled[b], shuffled[a] })
+
+			got := Aggregate(shuffled, asOf, w)
+			if got.Score != want.Score {
+				t.Fatalf("Score of shuffled reports = %v, want %v", got.Score, want.Score)
+			}
+
+			for k, c := range got.Contributions {
+				if *c != *want.Contributions[k] {
+					t.Fatalf("contribution %d of shuffled reports = %+v, want %+v", k, *c, *want.Contributions[k])
+				}
+			}
+		}
+	}
+}
+
+func TestAggregateBounds(t *testing.T) {
+	const asOf = int64(1000000)
+
+	w := testWeights
+	w.HalfLife = 24 * time.Hour
+	w.ReputationStep = DefaultReputationStep
+
+	rng := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 200; i++ {
+		reports := randomReports(rng, 1+rng.Intn(12), asOf)
+		res := Aggregate(reports, asOf, w)
+
+		// the score combines the counted weighted taints as independent pieces of evidence
+		clean := 1.0
+		for _, c := range res.Contributions {
+			if c.Counted {
+				clean *= 1 - math.Min(c.Taint, 100)/100
+			}
+		}
+		if want := 100 * (1 - clean); math.Abs(res.Score-want) > epsilon {
+			t.Fatalf("Score = %v, want 100×(1−Π(1−t/100)) = %v", res.Score, want)
+		}
+
+		if res.Score < 0 || res.Score > 100 {
+			t.Fatalf("Score = %v, out of [0, 100]", res.Score)
+		}
+
+		// no report lowers the score
+		extra := randomReports(rng, 1, asOf)[0]
+		extra.ID = "z"
+
+		more := Aggregate(append(reports, extra), asOf, w)
+		if more.Score < res.Score-epsilon {
+			t.Fatalf("Score fell from %v to %v with another report", res.Score, more.Score)
+		}
+	}
+}
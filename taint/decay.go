@@ -0,0 +1,31 @@
+package taint
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultHalfLife is the default time taint takes to lose half its weight.
+const DefaultHalfLife = 365 * 24 * time.Hour
+
+// DecayFactor returns the weight left, as of the unix timestamp asOf, of taint dating from the unix timestamp since:
+// 1 at since, halving every halfLife. Taint doesn't exist yet before since, so the weight is 0 if asOf is earlier.
+// A zero since is an unknown date, and a zero halfLife disables decay: both weigh 1.
+func DecayFactor(since int64, asOf int64, halfLife time.Duration) float64 {
+	if since == 0 {
+		return 1
+	}
+	if asOf < since {
+		return 0
+	}
+	if halfLife <= 0 {
+		return 1
+	}
+
+	return math.Exp2(-float64(asOf-since) / halfLife.Seconds())
+}
+
+// Decay returns score weighted by DecayFactor, rounded to the nearest integer.
+func Decay(score int, since int64, asOf int64, halfLife time.Duration) int {
+	return int(math.Round(float64(score) * DecayFactor(since, asOf, halfLife)))
+}
@@ -0,0 +1,262 @@
+package taint
+
+import (
+	"sort"
+
+	"github.com/perlin-network/safu-go/model"
+)
+
+// ChildrenRatio is the share of the source's taint held by every address the source's funds reach.
+const ChildrenRatio = 0.3
+
+// Asset is the taint an address holds in a single asset.
+type Asset struct {
+	Address string
+	Asset   string
+	Symbol  string
+	Score   int
+
+	// Since is the unix timestamp tainted funds reached the address at: the block time of the transfer that carried
+	// them, or of the first transfer in the asset for the source's entity. It is 0 if the block time isn't known.
+	Since int64
+}
+
+// Result is the taint spread by a source over a transaction graph.
+type Result struct {
+	Source string
+
+	// Scores holds the taint of the source and of every address its funds reach, in any asset.
+	Scores map[string]int
+
+	// Assets holds the taint of the source and of every address its funds reach, separately for every asset, sorted
+	// by address and asset.
+	Assets []*Asset
+
+	// Suppressed holds the taint the capped addresses the funds reached would have held without their cap.
+	Suppressed map[string]int
+}
+
+// Options tune how taint spreads over a graph.
+type Options struct {
+	// Entities holds the ID of the cluster of every clustered address. Addresses of the same entity share their
+	// taint.
+	Entities map[string]string
+
+	// Barriers holds the addresses taint stops at: they aren't tainted, and don't pass taint on.
+	Barriers map[string]bool
+
+	// Caps holds the highest taint of addresses known to be good. Taint reaching them is capped, and isn't passed on.
+	Caps map[string]int
+
+	// ChildrenRatio is the share of the source's taint held by every address the source's funds reach, the package's
+	// ChildrenRatio if 0.
+	ChildrenRatio float64
+}
+
+// Propagate spreads score from source along the edges of g. The source's entity holds score, and every address
+// reachable from it holds the options' ChildrenRatio of it. Cycles leading back to the source don't lower its taint.
+func Propagate(g *model.Graph, source string, score int, opts Options) *Result {
+	p := newPropagation(g, source, score, opts)
+
+	res := &Result{
+		Source:     source,
+		Scores:     make(map[string]int),
+		Suppressed: make(map[string]int),
+	}
+
+	var q []string
+
+	reach := func(address string) {
+		res.Scores[address] = p.score(address)
+
+		if _, ok := p.caps[address]; ok {
+			res.Suppressed[address] = p.uncappedScore(address)
+			return
+		}
+
+		q = append(q, address)
+	}
+
+	reach(source)
+	if _, ok := p.caps[source]; !ok {
+		for _, address := range p.entity(source) {
+			if address != source && !p.barriers[address] {
+				reach(address)
+			}
+		}
+	}
+
+	for len(q) != 0 {
+		u := q[0]
+		q = q[1:len(q):len(q)]
+
+		for _, v := range append(g.Successors(u), p.entity(u)...) {
+			if _, ok := res.Scores[v]; ok || p.barriers[v] {
+				continue
+			}
+
+			reach(v)
+		}
+	}
+
+	res.Assets = p.assets()
+
+	return res
+}
+
+type propagation struct {
+	g        *model.Graph
+	source   string
+	entities map[string]string
+	members  map[string][]string
+	barriers map[string]bool
+	caps     map[string]int
+
+	sourceTaint   int
+	childrenTaint int
+}
+
+func newPropagation(g *model.Graph, source string, score int, opts Options) *propagation {
+	ratio := opts.ChildrenRatio
+	if ratio == 0 {
+		ratio = ChildrenRatio
+	}
+
+	p := &propagation{
+		g:             g,
+		source:        source,
+		entities:      opts.Entities,
+		members:       make(map[string][]string),
+		barriers:      opts.Barriers,
+		caps:          opts.Caps,
+		sourceTaint:   score,
+		childrenTaint: int(float32(ratio) * float32(score)),
+	}
+
+	for _, address := range g.Addresses() {
+		if id, ok := p.entities[address]; ok {
+			p.members[id] = append(p.members[id], address)
+		}
+	}
+
+	return p
+}
+
+// entity returns the addresses of g that belong to the same entity as address, including address.
+func (p *propagation) entity(address string) []string {
+	id, ok := p.entities[address]
+	if !ok {
+		return []string{address}
+	}
+	return p.members[id]
+}
+
+// score returns the taint address holds, capped if it is known to be good.
+func (p *propagation) score(address string) int {
+	score := p.uncappedScore(address)
+
+	if max, ok := p.caps[address]; ok && score > max {
+		return max
+	}
+
+	return score
+}
+
+func (p *propagation) uncappedScore(address string) int {
+	if address == p.source {
+		return p.sourceTaint
+	}
+
+	if id, ok := p.entities[address]; ok && id == p.entities[p.source] {
+		return p.sourceTaint
+	}
+
+	return p.childrenTaint
+}
+
+// assets spreads taint separately for every asset: funds received in a token only taint the recipient's holdings of
+// that token. The source's entity holds its taint in every asset it sent.
+func (p *propagation) assets() []*Asset {
+	type state struct {
+		address string
+		asset   string
+	}
+
+	var q []state
+	visited := make(map[state]*Asset)
+	symbols := make(map[string]string)
+
+	reach := func(s state, since int64) {
+		if a, ok := visited[s]; ok {
+			// funds reaching an address through several transfers taint it from the earliest, and so do the funds it
+			// passes on, so that Since doesn't depend on the order transfers are visited in
+			if since != 0 && (a.Since == 0 || since < a.Since) {
+				a.Since = since
+
+				if _, ok := p.caps[s.address]; !ok {
+					q = append(q, s)
+				}
+			}
+			return
+		}
+		if s.address != p.source && p.barriers[s.address] {
+			return
+		}
+
+		visited[s] = &Asset{Address: s.address, Asset: s.asset, Symbol: symbols[s.asset], Score: p.score(s.address), Since: since}
+
+		if _, ok := p.caps[s.address]; !ok {
+			q = append(q, s)
+		}
+	}
+
+	sources := p.entity(p.source)
+	if _, ok := p.caps[p.source]; ok {
+		sources = []string{p.source}
+	}
+
+	for _, address := range sources {
+		for _, e := range p.g.OutEdges(address) {
+			symbols[e.Asset()] = e.Symbol()
+			reach(state{address: address, asset: e.Asset()}, e.Timestamp)
+		}
+	}
+
+	for len(q) != 0 {
+		u := q[0]
+		q = q[1:len(q):len(q)]
+
+		for _, e := range p.g.OutEdges(u.address) {
+			if e.Asset() != u.asset {
+				continue
+			}
+
+			// funds can't leave an address before reaching it
+			since := e.Timestamp
+			if from := visited[u].Since; since < from {
+				since = from
+			}
+
+			reach(state{address: e.To, asset: u.asset}, since)
+		}
+
+		// the other addresses of an entity are deemed to hold the same funds
+		for _, member := range p.entity(u.address) {
+			reach(state{address: member, asset: u.asset}, visited[u].Since)
+		}
+	}
+
+	assets := make([]*Asset, 0, len(visited))
+	for _, a := range visited {
+		assets = append(assets, a)
+	}
+
+	sort.Slice(assets, func(i, j int) bool {
+		if assets[i].Address != assets[j].Address {
+			return assets[i].Address < assets[j].Address
+		}
+		return assets[i].Asset < assets[j].Asset
+	})
+
+	return assets
+}